@@ -27,7 +27,7 @@ type BalanceResponse struct {
 func GetBalance(c *Client, ctx context.Context) (*BalanceResponse, error) {
 
 	req, err := utils.NewRequestBuilder(c.AuthToken).
-		SetBaseURL("https://api.deepseek.com/").
+		SetBaseURL(withTrailingSlash(c.BaseURL)).
 		SetPath("user/balance").
 		BuildGet(ctx)
 
@@ -35,7 +35,7 @@ func GetBalance(c *Client, ctx context.Context) (*BalanceResponse, error) {
 		return nil, fmt.Errorf("error building request: %w", err)
 	}
 
-	resp, err := HandleNormalRequest(*c, req)
+	resp, err := c.handleCall(OperationGetBalance, nil, req)
 
 	if err != nil {
 		return nil, fmt.Errorf("error sending request: %w", err)
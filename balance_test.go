@@ -34,3 +34,17 @@ func TestGetBalance(t *testing.T) {
 		assert.NotEmpty(t, info.ToppedUpBalance, "topped up balance should not be empty")
 	}
 }
+
+func TestGetBalance_Offline(t *testing.T) {
+	srv, client := newTestServer(t)
+	srv.SetBalance(deepseek.BalanceResponse{
+		IsAvailable:  false,
+		BalanceInfos: []deepseek.BalanceInfo{{Currency: "CNY", TotalBalance: "0.00"}},
+	})
+
+	balance, err := deepseek.GetBalance(client, context.Background())
+	require.NoError(t, err)
+	assert.False(t, balance.IsAvailable)
+	require.Len(t, balance.BalanceInfos, 1)
+	assert.Equal(t, "CNY", balance.BalanceInfos[0].Currency)
+}
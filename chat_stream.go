@@ -1,13 +1,10 @@
 package deepseek
 
 import (
-	"bufio"
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
-	"strings"
 )
 
 // StreamChatCompletionMessage represents a single message in a chat completion stream.
@@ -17,17 +14,21 @@ type StreamChatCompletionMessage struct {
 }
 
 // ChatCompletionStream is an interface for receiving streaming chat completion responses.
-type ChatCompletionStream interface {
-	Recv() (*StreamChatCompletionResponse, error)
-	Close() error
-}
+//
+// Recv returns io.EOF once the stream is complete. An error sent by the server inside the stream
+// is returned as an *APIError, and a stream that ends before "[DONE]" fails with ErrStreamTruncated.
+// In both cases PartialResponse returns what was received before the failure.
+type ChatCompletionStream = Stream[*StreamChatCompletionResponse]
 
 // chatCompletionStream implements the ChatCompletionStream interface.
 type chatCompletionStream struct {
-	ctx    context.Context    // Context for cancellation.
-	cancel context.CancelFunc // Cancel function for the context.
-	resp   *http.Response     // HTTP response from the API call.
-	reader *bufio.Reader      // Reader for the response body.
+	ctx       context.Context    // Context for cancellation.
+	cancel    context.CancelFunc // Cancel function for the context.
+	resp      *http.Response     // HTTP response from the API call.
+	reader    *streamReader      // Reader for the events of the response body.
+	limiter   *RateLimiter       // Rate limiter to reconcile once the usage is known (optional).
+	estimated int                // Tokens reserved on the rate limiter for this stream.
+	received  *StreamAccumulator // Chunks received so far, for PartialResponse.
 }
 
 // StreamDelta represents a delta in the chat completion stream.
@@ -40,10 +41,10 @@ type StreamDelta struct {
 
 // StreamChoices represents a choice in the chat completion stream.
 type StreamChoices struct {
-	Index        int         `json:"index"`                   // Index of the choice.
-	Delta        StreamDelta `json:"delta"`                   // Delta information for the choice.
-	FinishReason *string     `json:"finish_reason,omitempty"` // Reason for finishing the generation.
-	Logprobs     *Logprobs   `json:"logprobs,omitempty"`      // Log probabilities for the generated tokens.
+	Index        int           `json:"index"`                   // Index of the choice.
+	Delta        StreamDelta   `json:"delta"`                   // Delta information for the choice.
+	FinishReason *FinishReason `json:"finish_reason,omitempty"` // Reason for finishing the generation.
+	Logprobs     *Logprobs     `json:"logprobs,omitempty"`      // Log probabilities for the generated tokens.
 }
 
 // StreamChatCompletionResponse represents a single response from a streaming chat completion API call.
@@ -58,29 +59,31 @@ type StreamChatCompletionResponse struct {
 
 // Recv receives the next response from the stream.
 func (s *chatCompletionStream) Recv() (*StreamChatCompletionResponse, error) {
-	reader := s.reader
-	for {
-		line, err := reader.ReadString('\n') // Read until newline
-		if err != nil {
-			if err == io.EOF {
-				return nil, io.EOF
-			}
-			return nil, fmt.Errorf("error reading stream: %w", err)
-		}
+	data, err := s.reader.next()
+	if err != nil {
+		return nil, err
+	}
+	var response StreamChatCompletionResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("unmarshal error: %w, raw data: %s", err, data)
+	}
+	s.received.Add(&response)
+	if response.Usage != nil && s.limiter != nil {
+		s.limiter.Reconcile(s.estimated, response.Usage.TotalTokens)
+		s.limiter = nil
+	}
+	return &response, nil
+}
 
-		line = strings.TrimSpace(line)
-		if line == "data: [DONE]" {
-			return nil, io.EOF // End of stream
-		}
-		if len(line) > 6 && line[:6] == "data: " {
-			trimmed := line[6:] // Trim the "data: " prefix
-			var response StreamChatCompletionResponse
-			if err := json.Unmarshal([]byte(trimmed), &response); err != nil {
-				return nil, fmt.Errorf("unmarshal error: %w, raw data: %s", err, trimmed)
-			}
-			return &response, nil
-		}
+// PartialResponse returns the response accumulated from the chunks a stream has returned so far,
+// e.g. after Recv failed with an in-band *APIError or ErrStreamTruncated. It only supports
+// streams created by Client.CreateChatCompletionStream.
+func PartialResponse(stream ChatCompletionStream) (*ChatCompletionResponse, error) {
+	s, ok := stream.(*chatCompletionStream)
+	if !ok {
+		return nil, fmt.Errorf("partial responses are not recorded by %T", stream)
 	}
+	return s.received.Response()
 }
 
 // Close terminates the stream.
@@ -7,10 +7,13 @@ import (
 	"fmt"
 	"github.com/cohesion-org/deepseek-go/utils"
 	"io"
+	"net"
 	"testing"
+	"time"
 
 	"github.com/cohesion-org/deepseek-go"
 	"github.com/cohesion-org/deepseek-go/constants"
+	"github.com/cohesion-org/deepseek-go/deepseektest"
 	"github.com/cohesion-org/deepseek-go/internal/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -18,8 +21,7 @@ import (
 
 func TestCreateChatCompletionStream(t *testing.T) {
 	testutil.SkipIfShort(t)
-	config := testutil.LoadTestConfig(t)
-	client := deepseek.NewClient(config.APIKey)
+	client, config := testutil.NewCassetteClient(t, "chat_completion_stream")
 
 	ctx, cancel := context.WithTimeout(context.Background(), config.TestTimeout)
 	defer cancel()
@@ -46,8 +48,10 @@ func TestCreateChatCompletionStream(t *testing.T) {
 		}
 
 		if len(resp.Choices) > 0 {
-			contentBuffer += resp.Choices[0].Delta.Content
-			if resp.Choices[0].FinishReason != "" {
+			if resp.Choices[0].Delta.Content != nil {
+				contentBuffer += *resp.Choices[0].Delta.Content
+			}
+			if resp.Choices[0].FinishReason != nil && *resp.Choices[0].FinishReason != "" {
 				receivedFinishReason = true
 			}
 		}
@@ -60,8 +64,7 @@ func TestCreateChatCompletionStream(t *testing.T) {
 
 func TestStreamingMultiChat(t *testing.T) {
 	testutil.SkipIfShort(t)
-	config := testutil.LoadTestConfig(t)
-	client := deepseek.NewClient(config.APIKey)
+	client, config := testutil.NewCassetteClient(t, "streaming_multi_chat")
 	ctx, cancel := context.WithTimeout(context.Background(), config.TestTimeout)
 	defer cancel()
 
@@ -146,11 +149,13 @@ func streamChatCompletion(
 
 		if len(resp.Choices) > 0 {
 			chunk := resp.Choices[0]
-			if chunk.Delta.Role != "" {
+			if chunk.Delta.Role != nil && *chunk.Delta.Role != "" {
 				receivedRole = true
-				assert.Equal(t, constants.ChatMessageRoleAssistant, chunk.Delta.Role)
+				assert.Equal(t, constants.ChatMessageRoleAssistant, *chunk.Delta.Role)
+			}
+			if chunk.Delta.Content != nil {
+				fullMessage += *chunk.Delta.Content
 			}
-			fullMessage += chunk.Delta.Content
 		}
 	}
 
@@ -164,8 +169,7 @@ func TestStreamingWithToolCalls(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping test in short mode.")
 	}
-	config := testutil.LoadTestConfig(t)
-	client := deepseek.NewClient(config.APIKey)
+	client, config := testutil.NewCassetteClient(t, "streaming_tool_calls")
 	ctx, cancel := context.WithTimeout(context.Background(), config.TestTimeout)
 	defer cancel()
 
@@ -229,8 +233,8 @@ func TestStreamingWithToolCalls(t *testing.T) {
 			chunk := resp.Choices[0]
 
 			// Track regular content
-			if chunk.Delta.Content != "" {
-				fullMessage += chunk.Delta.Content
+			if chunk.Delta.Content != nil {
+				fullMessage += *chunk.Delta.Content
 			}
 
 			if len(chunk.Delta.ToolCalls) > 0 {
@@ -259,3 +263,132 @@ func TestStreamingWithToolCalls(t *testing.T) {
 
 	fmt.Printf("Arguments: %s", fullToolCall.arguments)
 }
+
+func TestCreateChatCompletionStream_EventStreamFormats(t *testing.T) {
+	srv, client := newTestServer(t)
+	srv.Enqueue(deepseektest.RouteChat, deepseektest.Response{
+		Content:   "Hello there",
+		KeepAlive: true,
+		// A chunk split over two data lines, without a space after the colon, with CRLF line endings.
+		RawStreamEnd: "data:{\"id\":\"raw\",\"object\":\"chat.completion.chunk\",\r\n" +
+			"data:\"choices\":[{\"index\":0,\"delta\":{\"content\":\"!\"}}]}\r\n\r\n",
+	})
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), testChatRequest())
+	require.NoError(t, err)
+	defer stream.Close()
+
+	resp, err := deepseek.AccumulateStream(stream)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello there!", resp.Choices[0].Message.Content)
+}
+
+func TestCreateChatCompletionStream_ErrorEvent(t *testing.T) {
+	srv, client := newTestServer(t)
+	srv.Enqueue(deepseektest.RouteChat, deepseektest.Response{
+		Content:      "Partial",
+		RawStreamEnd: "event: error\ndata: {\"error\":{\"message\":\"Server overloaded\",\"code\":503}}\n\n",
+	})
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), testChatRequest())
+	require.NoError(t, err)
+	defer stream.Close()
+
+	_, err = deepseek.AccumulateStream(stream)
+	var apiErr *deepseek.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "Server overloaded", apiErr.Message)
+	assert.Equal(t, 503, apiErr.APICode)
+}
+
+func TestCreateChatCompletionStream_InBandError(t *testing.T) {
+	srv, client := newTestServer(t)
+	srv.Enqueue(deepseektest.RouteChat, deepseektest.Response{
+		Content:     "The answer is",
+		StreamError: &deepseektest.ErrorBody{Message: "Model overloaded", Type: "server_error"},
+	})
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), testChatRequest())
+	require.NoError(t, err)
+	defer stream.Close()
+
+	var recvErr error
+	for recvErr == nil {
+		_, recvErr = stream.Recv()
+	}
+	var apiErr *deepseek.APIError
+	require.ErrorAs(t, recvErr, &apiErr)
+	assert.Equal(t, "Model overloaded", apiErr.Message)
+	assert.NotErrorIs(t, recvErr, io.EOF)
+
+	_, err = stream.Recv()
+	assert.Equal(t, recvErr, err, "the error is returned again by later calls")
+
+	partial, err := deepseek.PartialResponse(stream)
+	require.NoError(t, err)
+	assert.Equal(t, "The answer is", partial.Choices[0].Message.Content)
+}
+
+func TestCreateChatCompletionStream_Truncated(t *testing.T) {
+	srv, client := newTestServer(t)
+	srv.Enqueue(deepseektest.RouteChat,
+		deepseektest.Response{Content: "Cut off", OmitDone: true},
+		deepseektest.Response{Content: "Complete"},
+	)
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), testChatRequest())
+	require.NoError(t, err)
+	defer stream.Close()
+	partial, err := deepseek.AccumulateStream(stream)
+	assert.ErrorIs(t, err, deepseek.ErrStreamTruncated)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	require.NotNil(t, partial)
+	assert.Equal(t, "Cut off", partial.Choices[0].Message.Content)
+
+	stream, err = client.CreateChatCompletionStream(context.Background(), testChatRequest())
+	require.NoError(t, err)
+	defer stream.Close()
+	resp, err := deepseek.AccumulateStream(stream)
+	require.NoError(t, err, "a stream terminated by [DONE] ends with a clean io.EOF")
+	assert.Equal(t, "Complete", resp.Choices[0].Message.Content)
+}
+
+func TestCreateChatCompletionStream_IdleTimeout(t *testing.T) {
+	srv, client := newTestServer(t, deepseek.WithStreamIdleTimeout(100*time.Millisecond))
+	srv.Enqueue(deepseektest.RouteChat,
+		deepseektest.Response{Chunks: []string{"Slow", " answer"}, ChunkDelay: time.Second},
+		deepseektest.Response{Chunks: []string{"Steady", " answer"}, ChunkDelay: 20 * time.Millisecond, KeepAlive: true},
+	)
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), testChatRequest())
+	require.NoError(t, err)
+	defer stream.Close()
+
+	start := time.Now()
+	partial, err := deepseek.AccumulateStream(stream)
+	assert.Less(t, time.Since(start), time.Second, "the idle timeout should abort the stream early")
+	require.ErrorIs(t, err, deepseek.ErrStreamIdleTimeout)
+	var netErr net.Error
+	require.ErrorAs(t, err, &netErr)
+	assert.True(t, netErr.Timeout())
+	require.NotNil(t, partial, "the role chunk was received before the stall")
+	assert.Equal(t, "assistant", partial.Choices[0].Message.Role)
+
+	// Time spent by the consumer between calls to Recv does not count as idle time.
+	stream, err = client.CreateChatCompletionStream(context.Background(), testChatRequest())
+	require.NoError(t, err)
+	defer stream.Close()
+	var content string
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		if chunk.Choices[0].Delta.Content != nil {
+			content += *chunk.Choices[0].Delta.Content
+		}
+		time.Sleep(150 * time.Millisecond)
+	}
+	assert.Equal(t, "Steady answer", content)
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/cohesion-org/deepseek-go"
 	"github.com/cohesion-org/deepseek-go/constants"
+	"github.com/cohesion-org/deepseek-go/deepseektest"
 	"github.com/cohesion-org/deepseek-go/internal/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -13,8 +14,7 @@ import (
 
 func TestCreateChatCompletion(t *testing.T) {
 	testutil.SkipIfShort(t)
-	config := testutil.LoadTestConfig(t)
-	client := deepseek.NewClient(config.APIKey)
+	client, config := testutil.NewCassetteClient(t, "chat_completion")
 
 	tests := []struct {
 		name        string
@@ -83,8 +83,7 @@ func TestCreateChatCompletion(t *testing.T) {
 
 func TestMultiChatConversation(t *testing.T) {
 	testutil.SkipIfShort(t)
-	config := testutil.LoadTestConfig(t)
-	client := deepseek.NewClient(config.APIKey)
+	client, config := testutil.NewCassetteClient(t, "multi_chat_conversation")
 	ctx, cancel := context.WithTimeout(context.Background(), config.TestTimeout)
 	defer cancel()
 
@@ -151,3 +150,26 @@ func TestMultiChatConversation(t *testing.T) {
 		assert.NotZero(t, resp.Usage.TotalTokens)
 	})
 }
+
+func TestCreateChatCompletion_Offline(t *testing.T) {
+	srv, client := newTestServer(t)
+	srv.Enqueue(deepseektest.RouteChat, deepseektest.Response{Content: "Hello!"})
+
+	resp, err := client.CreateChatCompletion(context.Background(), &deepseek.ChatCompletionRequest{
+		Model: deepseek.DeepSeekChat,
+		Messages: []deepseek.ChatCompletionMessage{
+			{Role: constants.ChatMessageRoleUser, Content: "Say hello!"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "chat.completion", resp.Object)
+	assert.Equal(t, "Hello!", resp.Choices[0].Message.Content)
+	assert.NotZero(t, resp.Usage.TotalTokens)
+
+	last, ok := srv.LastRequest()
+	require.True(t, ok)
+	req, err := last.ChatRequest()
+	require.NoError(t, err)
+	require.NotNil(t, req.Stream)
+	assert.False(t, *req.Stream)
+}
@@ -1,7 +1,6 @@
 package deepseek
 
 import (
-	"bufio"
 	"context"
 	"fmt"
 
@@ -23,6 +22,15 @@ func (c *Client) CreateChatCompletion(
 	}
 	defer tcancel()
 
+	estimated := estimateRequestTokens(request)
+	if err := c.RateLimiter.Wait(ctx, estimated); err != nil {
+		return nil, fmt.Errorf("error waiting for rate limiter: %w", err)
+	}
+	// The reservation is refunded unless the response reports the tokens actually used.
+	used := 0
+	defer func() { c.RateLimiter.Reconcile(estimated, used) }()
+
+	request = c.mapModel(request)
 	request.Stream = utils.BoolPtr(false)
 	req, err := utils.NewRequestBuilder(c.AuthToken).
 		SetBaseURL(c.BaseURL).
@@ -33,7 +41,7 @@ func (c *Client) CreateChatCompletion(
 	if err != nil {
 		return nil, fmt.Errorf("error building request: %w", err)
 	}
-	resp, err := HandleSendChatCompletionRequest(*c, req)
+	resp, err := c.handleCall(OperationChatCompletion, request, req)
 
 	if err != nil {
 		return nil, fmt.Errorf("error sending request: %w", err)
@@ -44,11 +52,15 @@ func (c *Client) CreateChatCompletion(
 		return nil, HandleAPIError(resp)
 	}
 
+	if err := c.normalizeResponse(resp); err != nil {
+		return nil, err
+	}
 	updatedResp, err := HandleChatCompletionResponse(resp)
 
 	if err != nil {
 		return nil, fmt.Errorf("error decoding response: %w", err)
 	}
+	used = updatedResp.Usage.TotalTokens
 
 	return updatedResp, err
 }
@@ -62,11 +74,31 @@ func (c *Client) CreateChatCompletionStream(
 		return nil, fmt.Errorf("request cannot be nil")
 	}
 
-	ctx, _, err := getTimeoutContext(ctx, c.Timeout)
+	ctx, tcancel, err := getTimeoutContext(ctx, c.Timeout)
 	if err != nil {
 		return nil, err
 	}
+	// The request uses the stream's context, so that Close and the idle timeout can abort it.
+	ctx, cancel := context.WithCancel(ctx)
+	stop := func() {
+		cancel()
+		tcancel()
+	}
+
+	estimated := estimateRequestTokens(request)
+	if err := c.RateLimiter.Wait(ctx, estimated); err != nil {
+		stop()
+		return nil, fmt.Errorf("error waiting for rate limiter: %w", err)
+	}
+	// Until the stream takes over the reservation, returning refunds it.
+	refund := true
+	defer func() {
+		if refund {
+			c.RateLimiter.Reconcile(estimated, 0)
+		}
+	}()
 
+	request = c.mapModel(request)
 	request.Stream = utils.BoolPtr(true)
 	req, err := utils.NewRequestBuilder(c.AuthToken).
 		SetBaseURL(c.BaseURL).
@@ -75,30 +107,36 @@ func (c *Client) CreateChatCompletionStream(
 		BuildStream(ctx)
 
 	if err != nil {
+		stop()
 		return nil, fmt.Errorf("error building request: %w", err)
 	}
 
-	resp, err := HandleSendChatCompletionRequest(*c, req)
+	resp, err := c.handleCall(OperationChatCompletionStream, request, req)
 	if err != nil {
+		stop()
 		return nil, err
 	}
 
 	if resp.StatusCode >= 400 {
+		defer stop()
 		return nil, HandleAPIError(resp)
 	}
 
-	ctx, cancel := context.WithCancel(ctx)
 	stream := &chatCompletionStream{
-		ctx:    ctx,
-		cancel: cancel,
-		resp:   resp,
-		reader: bufio.NewReader(resp.Body),
-	}
+		ctx:       ctx,
+		cancel:    stop,
+		resp:      resp,
+		reader:    c.streamReader(resp, stop),
+		limiter:   c.RateLimiter,
+		estimated: estimated,
+		received:  NewStreamAccumulator(),
+	}
+	refund = false
 	return stream, nil
 }
 
 // CreateFIMCompletion is a beta feature. It sends a FIM completion request and returns the generated response.
-// the base URL is set to "https://api.deepseek.com/beta/"
+// The request is sent to the "completions" endpoint under the client's beta base URL, see WithBetaBaseURL.
 func (c *Client) CreateFIMCompletion(
 	ctx context.Context,
 	request *FIMCompletionRequest,
@@ -109,16 +147,16 @@ func (c *Client) CreateFIMCompletion(
 	if request.MaxTokens > 4000 {
 		return nil, fmt.Errorf("max tokens must be <= 4000")
 	}
-	baseURL := "https://api.deepseek.com/beta/"
+	request = c.mapFIMModel(request)
 	req, err := utils.NewRequestBuilder(c.AuthToken).
-		SetBaseURL(baseURL).
-		SetPath("/completions").
+		SetBaseURL(c.betaBaseURL()).
+		SetPath("completions").
 		SetBodyFromStruct(request).
 		Build(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("error building request: %w", err)
 	}
-	resp, err := HandleSendChatCompletionRequest(*c, req)
+	resp, err := c.handleCall(OperationFIMCompletion, request, req)
 	if err != nil {
 		return nil, fmt.Errorf("error sending request: %w", err)
 	}
@@ -126,6 +164,9 @@ func (c *Client) CreateFIMCompletion(
 	if resp.StatusCode >= 400 {
 		return nil, HandleAPIError(resp)
 	}
+	if err := c.normalizeResponse(resp); err != nil {
+		return nil, err
+	}
 	updatedResp, err := HandleFIMCompletionRequest(resp)
 	if err != nil {
 		return nil, fmt.Errorf("error decoding response: %w", err)
@@ -138,34 +179,37 @@ func (c *Client) CreateFIMStreamCompletion(
 	ctx context.Context,
 	request *FIMStreamCompletionRequest,
 ) (FIMChatCompletionStream, error) {
-	baseURL := "https://api.deepseek.com/beta/"
+	ctx, cancel := context.WithCancel(ctx)
 
+	request = c.mapFIMStreamModel(request)
 	request.Stream = true
 	req, err := utils.NewRequestBuilder(c.AuthToken).
-		SetBaseURL(baseURL).
-		SetPath("/completions"). //Note to maintianer: This is a really bad implementation with manual path insertion. Please create an issue.
+		SetBaseURL(c.betaBaseURL()).
+		SetPath("completions").
 		SetBodyFromStruct(request).
 		BuildStream(ctx)
 
 	if err != nil {
+		cancel()
 		return nil, fmt.Errorf("error building request: %w", err)
 	}
 
-	resp, err := HandleSendChatCompletionRequest(*c, req)
+	resp, err := c.handleCall(OperationFIMCompletionStream, request, req)
 	if err != nil {
+		cancel()
 		return nil, err
 	}
 
 	if resp.StatusCode >= 400 {
+		defer cancel()
 		return nil, HandleAPIError(resp)
 	}
 
-	ctx, cancel := context.WithCancel(ctx)
 	stream := &fimCompletionStream{
 		ctx:    ctx,
 		cancel: cancel,
 		resp:   resp,
-		reader: bufio.NewReader(resp.Body),
+		reader: c.streamReader(resp, cancel),
 	}
 	return stream, nil
 }
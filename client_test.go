@@ -45,8 +45,8 @@ func TestCreateChatCompletion_ErrorHandling(t *testing.T) {
 	if !ok {
 		t.Fatalf("expected APIError, got %T", err)
 	}
-	if apiErr.Message != "Bad request" {
-		t.Errorf("expected error message 'Bad request', got %s", apiErr.Message)
+	if apiErr.Message != "invalid request" {
+		t.Errorf("expected error message 'invalid request', got %s", apiErr.Message)
 	}
 }
 
@@ -71,7 +71,58 @@ func TestCreateChatCompletionStream_ErrorHandling(t *testing.T) {
 	if !ok {
 		t.Fatalf("expected APIError, got %T", err)
 	}
-	if apiErr.Message != "Bad request" {
-		t.Errorf("expected error message 'Bad request', got %s", apiErr.Message)
+	if apiErr.Message != "stream error" {
+		t.Errorf("expected error message 'stream error', got %s", apiErr.Message)
+	}
+}
+
+func TestClient_EndpointURLs(t *testing.T) {
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	fim := &deepseek.FIMCompletionRequest{Model: deepseek.DeepSeekChat, Prompt: "def add(a, b):"}
+
+	// Without a beta base URL, the beta endpoints live under BaseURL, which may lack a trailing slash.
+	client, err := deepseek.NewClientWithOptions("token", deepseek.WithBaseURL(srv.URL+"/proxy"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = client.CreateFIMCompletion(ctx, fim)
+	_, _ = deepseek.GetBalance(client, ctx)
+	_, _ = deepseek.ListAllModels(client, ctx)
+
+	client, err = deepseek.NewClientWithOptions("token",
+		deepseek.WithBaseURL(srv.URL+"/proxy/"),
+		deepseek.WithBetaBaseURL(srv.URL+"/coder/v1"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = client.CreateFIMCompletion(ctx, fim)
+	stream, err := client.CreateFIMStreamCompletion(ctx, &deepseek.FIMStreamCompletionRequest{Model: deepseek.DeepSeekChat, Prompt: "x"})
+	if err == nil {
+		_ = stream.Close()
+	}
+
+	want := []string{
+		"/proxy/beta/completions",
+		"/proxy/user/balance",
+		"/proxy/models",
+		"/coder/v1/completions",
+		"/coder/v1/completions",
+	}
+	if len(paths) != len(want) {
+		t.Fatalf("expected requests to %v, got %v", want, paths)
+	}
+	for i := range want {
+		if paths[i] != want[i] {
+			t.Errorf("request %d: expected path %s, got %s", i, want[i], paths[i])
+		}
 	}
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 )
 
@@ -17,12 +18,23 @@ type HTTPDoer interface {
 
 // Client is the main struct for interacting with the Deepseek API.
 type Client struct {
-	AuthToken string        // The authentication token for the API
-	BaseURL   string        // The base URL for the API
-	Timeout   time.Duration // The timeout for the current Client
-	Path      string        // The path for the API request. Defaults to "chat/completions"
+	AuthToken string // The authentication token for the API
+	// KeyProvider, if set, supplies the API key of each request instead of AuthToken, see WithKeyProvider.
+	KeyProvider KeyProvider
+	BaseURL     string // The base URL for the API
+	// BetaBaseURL is the base URL of the beta endpoints, such as FIM completion.
+	// If empty, it is derived from BaseURL by appending "beta/".
+	BetaBaseURL string
+	Timeout     time.Duration // The timeout for the current Client
+	Path        string        // The path for the API request. Defaults to "chat/completions"
 
-	HTTPClient HTTPDoer // The HTTP client to send the request and get the response
+	HTTPClient  HTTPDoer     // The HTTP client to send the request and get the response
+	RetryPolicy *RetryPolicy // Optional retry policy. Requests are sent only once if nil.
+	RateLimiter *RateLimiter // Optional client-side rate limiter for chat completions.
+	Middleware  []Middleware // Middleware applied to every request, see WithMiddleware.
+	Provider    Provider     // Optional provider the API is served by, see WithProvider.
+
+	StreamIdleTimeout time.Duration // Longest wait for data while a stream's Recv blocks. 0 disables it.
 }
 
 // NewClient creates a new client with an authentication token and an optional custom baseURL.
@@ -57,6 +69,7 @@ type Option func(*Client) error
 // NewClientWithOptions creates a new client with required authentication token and optional configurations.
 // Defaults:
 // - BaseURL: "https://api.deepseek.com/"
+// - BetaBaseURL: BaseURL followed by "beta/"
 // - Timeout: 5 minutes
 func NewClientWithOptions(authToken string, opts ...Option) (*Client, error) {
 	client := &Client{
@@ -83,6 +96,32 @@ func WithBaseURL(url string) Option {
 	}
 }
 
+// WithBetaBaseURL sets the base URL of the beta endpoints, such as FIM completion, e.g. to route
+// them through a proxy or to a provider hosting the completions endpoint under another prefix.
+// Defaults to BaseURL followed by "beta/".
+func WithBetaBaseURL(url string) Option {
+	return func(c *Client) error {
+		c.BetaBaseURL = url
+		return nil
+	}
+}
+
+// betaBaseURL returns the base URL of the beta endpoints, ending with a slash.
+func (c *Client) betaBaseURL() string {
+	if c.BetaBaseURL != "" {
+		return withTrailingSlash(c.BetaBaseURL)
+	}
+	return withTrailingSlash(c.BaseURL) + "beta/"
+}
+
+// withTrailingSlash makes sure that paths can be appended to a base URL.
+func withTrailingSlash(url string) string {
+	if strings.HasSuffix(url, "/") {
+		return url
+	}
+	return url + "/"
+}
+
 // WithTimeout sets the timeout for API requests
 func WithTimeout(d time.Duration) Option {
 	return func(c *Client) error {
@@ -106,6 +145,18 @@ func WithTimeoutString(s string) Option {
 	}
 }
 
+// WithStreamIdleTimeout aborts streams that receive no data for d while Recv is waiting,
+// independently of the overall Timeout. Recv then fails with ErrStreamIdleTimeout. 0 disables the timeout.
+func WithStreamIdleTimeout(d time.Duration) Option {
+	return func(c *Client) error {
+		if d < 0 {
+			return fmt.Errorf("stream idle timeout cannot be negative")
+		}
+		c.StreamIdleTimeout = d
+		return nil
+	}
+}
+
 // WithPath sets the path for the API request. Defaults to "chat/completions", if not set.
 // Example usages would be "/c/chat/" or any http after the baseURL extension
 func WithPath(path string) Option {
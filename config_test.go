@@ -63,6 +63,17 @@ func TestNewClientWithOptions(t *testing.T) {
 			opts:        []deepseek.Option{deepseek.WithTimeout(-1 * time.Second)},
 			expectError: true,
 		},
+		{
+			name:            "disabled stream idle timeout",
+			opts:            []deepseek.Option{deepseek.WithStreamIdleTimeout(0)},
+			expectedURL:     "https://api.deepseek.com/",
+			expectedTimeout: 5 * time.Minute,
+		},
+		{
+			name:        "invalid stream idle timeout",
+			opts:        []deepseek.Option{deepseek.WithStreamIdleTimeout(-1 * time.Second)},
+			expectError: true,
+		},
 	}
 
 	for _, tt := range tests {
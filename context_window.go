@@ -0,0 +1,191 @@
+package deepseek
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrContextBudgetExceeded is returned by a ContextStrategy when the messages that must be kept,
+// i.e. the system messages and the latest message, do not fit in the token budget on their own.
+var ErrContextBudgetExceeded = errors.New("messages exceed the token budget")
+
+// TokenCounter counts the tokens of a message. EstimateMessageTokens is used when none is set;
+// set one backed by a real tokenizer for exact budgets.
+type TokenCounter func(message ChatCompletionMessage) int
+
+// ContextStrategy trims the history of a conversation to fit a token budget.
+//
+// Strategies keep system messages and the latest message, and never separate an assistant message
+// with tool calls from the tool results that answer it, since the API rejects a tool result whose
+// ToolCallID has no matching call.
+type ContextStrategy interface {
+	// Trim returns the messages to send, using at most budget tokens. The input is not modified.
+	Trim(messages []ChatCompletionMessage, budget int) ([]ChatCompletionMessage, error)
+}
+
+// SlidingWindow keeps the system messages and as many of the most recent messages as fit in the budget.
+type SlidingWindow struct {
+	Counter TokenCounter // Counts the tokens of a message. Defaults to EstimateMessageTokens.
+}
+
+// Trim implements ContextStrategy.
+func (s SlidingWindow) Trim(messages []ChatCompletionMessage, budget int) ([]ChatCompletionMessage, error) {
+	units := splitContextUnits(messages, s.Counter)
+	return fitContextUnits(units, budget)
+}
+
+// LastTurns keeps the system messages and the last Turns turns, a turn being a user message and the
+// messages that follow it. Older turns of those are dropped as well if they do not fit in the budget.
+type LastTurns struct {
+	Turns   int          // Number of turns to keep. Values < 1 keep only the latest turn.
+	Counter TokenCounter // Counts the tokens of a message. Defaults to EstimateMessageTokens.
+}
+
+// Trim implements ContextStrategy.
+func (s LastTurns) Trim(messages []ChatCompletionMessage, budget int) ([]ChatCompletionMessage, error) {
+	units := splitContextUnits(messages, s.Counter)
+	turns := max(s.Turns, 1)
+	last := units[len(units)-1].turn
+	for i := range units {
+		if !units[i].system && units[i].turn <= last-turns {
+			units[i].dropped = true
+		}
+	}
+	return fitContextUnits(units, budget)
+}
+
+// DropToolResultsFirst removes earlier tool calls and their results, oldest first, before dropping any
+// other message. Tool calls of the latest turn are kept, since the model is still working with them.
+// If that is not enough, the remaining messages are trimmed like SlidingWindow does.
+type DropToolResultsFirst struct {
+	Counter TokenCounter // Counts the tokens of a message. Defaults to EstimateMessageTokens.
+}
+
+// Trim implements ContextStrategy.
+func (s DropToolResultsFirst) Trim(messages []ChatCompletionMessage, budget int) ([]ChatCompletionMessage, error) {
+	units := splitContextUnits(messages, s.Counter)
+	last := units[len(units)-1].turn
+	for i := range units {
+		if contextTokens(units) <= budget {
+			break
+		}
+		if units[i].toolCalls && units[i].turn < last {
+			units[i].dropped = true
+		}
+	}
+	return fitContextUnits(units, budget)
+}
+
+// contextUnit is a group of messages that is kept or dropped as a whole.
+type contextUnit struct {
+	messages  []ChatCompletionMessage
+	tokens    int
+	turn      int  // Number of user messages up to and including this unit.
+	system    bool // System messages are always kept.
+	toolCalls bool // An assistant message with tool calls, followed by its tool results.
+	dropped   bool
+}
+
+// splitContextUnits groups messages into units. An empty history yields a single empty unit.
+func splitContextUnits(messages []ChatCompletionMessage, counter TokenCounter) []contextUnit {
+	if counter == nil {
+		counter = EstimateMessageTokens
+	}
+	units := make([]contextUnit, 0, len(messages))
+	turn := 0
+	for i := 0; i < len(messages); {
+		m := messages[i]
+		if m.Role == ChatMessageRoleUser {
+			turn++
+		}
+		unit := contextUnit{turn: turn, system: m.Role == ChatMessageRoleSystem}
+		end := i + 1
+		if m.Role == ChatMessageRoleAssistant && len(m.ToolCalls) > 0 {
+			unit.toolCalls = true
+			for end < len(messages) && messages[end].Role == ChatMessageRoleTool {
+				end++
+			}
+		}
+		unit.messages = messages[i:end]
+		for _, msg := range unit.messages {
+			unit.tokens += counter(msg)
+		}
+		units = append(units, unit)
+		i = end
+	}
+	if len(units) == 0 {
+		units = append(units, contextUnit{})
+	}
+	return units
+}
+
+// fitContextUnits drops the oldest units that are not system messages until the rest fits in the budget.
+// Tool results left without their call, e.g. at the start of the history, are dropped as well, and so
+// are the replies of a turn whose user message was dropped.
+func fitContextUnits(units []contextUnit, budget int) ([]ChatCompletionMessage, error) {
+	latest := len(units) - 1
+	for i := range units {
+		if i == latest || units[i].system || units[i].dropped {
+			continue
+		}
+		if units[i].messages[0].Role == ChatMessageRoleTool || contextTokens(units) > budget {
+			units[i].dropped = true
+		}
+	}
+	// Dropping may cut a turn in half: drop its remaining replies too, so that the kept history
+	// starts with a user message rather than an assistant message or tool results.
+	trimmed := false
+	for i := range units {
+		if i == latest || units[i].system {
+			continue
+		}
+		if units[i].dropped {
+			trimmed = true
+			continue
+		}
+		if !trimmed || units[i].messages[0].Role == ChatMessageRoleUser {
+			break
+		}
+		units[i].dropped = true
+	}
+	if tokens := contextTokens(units); tokens > budget {
+		return nil, fmt.Errorf("%w: %d tokens are required, the budget is %d", ErrContextBudgetExceeded, tokens, budget)
+	}
+
+	var messages []ChatCompletionMessage
+	for _, unit := range units {
+		if !unit.dropped {
+			messages = append(messages, unit.messages...)
+		}
+	}
+	return messages, nil
+}
+
+// contextTokens returns the tokens of the units that are kept.
+func contextTokens(units []contextUnit) int {
+	tokens := 0
+	for _, unit := range units {
+		if !unit.dropped {
+			tokens += unit.tokens
+		}
+	}
+	return tokens
+}
+
+// FitRequest trims the messages of request with strategy, so that the request, including its tools
+// and MaxTokens, fits in a context window of budget tokens, e.g. 64000 for deepseek-chat.
+func FitRequest(request *ChatCompletionRequest, strategy ContextStrategy, budget int) error {
+	if request == nil {
+		return fmt.Errorf("request cannot be nil")
+	}
+	reserved := EstimateTokensFromMessages(&ChatCompletionRequest{Tools: request.Tools}).EstimatedTokens
+	if request.MaxTokens != nil {
+		reserved += *request.MaxTokens
+	}
+	messages, err := strategy.Trim(request.Messages, budget-reserved)
+	if err != nil {
+		return err
+	}
+	request.Messages = messages
+	return nil
+}
@@ -0,0 +1,146 @@
+package deepseek_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cohesion-org/deepseek-go"
+	"github.com/cohesion-org/deepseek-go/deepseektest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countMessages counts every message as 10 tokens, which keeps the budgets of the tests readable.
+func countMessages(deepseek.ChatCompletionMessage) int { return 10 }
+
+func message(role, content string) deepseek.ChatCompletionMessage {
+	return deepseek.ChatCompletionMessage{Role: role, Content: content}
+}
+
+func toolCallMessage(id string) deepseek.ChatCompletionMessage {
+	name := "lookup"
+	return deepseek.ChatCompletionMessage{
+		Role:      deepseek.ChatMessageRoleAssistant,
+		ToolCalls: []deepseek.ToolCall{{ID: &id, Function: deepseek.ToolCallFunction{Name: &name, Arguments: "{}"}}},
+	}
+}
+
+func toolResult(id string) deepseek.ChatCompletionMessage {
+	return deepseek.ChatCompletionMessage{Role: deepseek.ChatMessageRoleTool, ToolCallID: id, Content: "result " + id}
+}
+
+// testHistory has three turns; the second one called a tool.
+func testHistory() []deepseek.ChatCompletionMessage {
+	return []deepseek.ChatCompletionMessage{
+		message(deepseek.ChatMessageRoleSystem, "system"),
+		message(deepseek.ChatMessageRoleUser, "u1"),
+		message(deepseek.ChatMessageRoleAssistant, "a1"),
+		message(deepseek.ChatMessageRoleUser, "u2"),
+		toolCallMessage("call_1"),
+		toolResult("call_1"),
+		message(deepseek.ChatMessageRoleAssistant, "a2"),
+		message(deepseek.ChatMessageRoleUser, "u3"),
+	}
+}
+
+func contents(messages []deepseek.ChatCompletionMessage) []string {
+	var out []string
+	for _, m := range messages {
+		if m.ToolCallID != "" {
+			out = append(out, m.ToolCallID)
+		} else if len(m.ToolCalls) > 0 {
+			out = append(out, "call")
+		} else {
+			out = append(out, m.Content)
+		}
+	}
+	return out
+}
+
+func TestSlidingWindow(t *testing.T) {
+	strategy := deepseek.SlidingWindow{Counter: countMessages}
+
+	trimmed, err := strategy.Trim(testHistory(), 80)
+	require.NoError(t, err)
+	assert.Len(t, trimmed, 8, "everything fits")
+
+	trimmed, err = strategy.Trim(testHistory(), 60)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"system", "u2", "call", "call_1", "a2", "u3"}, contents(trimmed))
+
+	trimmed, err = strategy.Trim(testHistory(), 40)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"system", "u3"}, contents(trimmed), "the history starts with a user message")
+
+	_, err = strategy.Trim(testHistory(), 15)
+	assert.ErrorIs(t, err, deepseek.ErrContextBudgetExceeded)
+}
+
+func TestLastTurns(t *testing.T) {
+	trimmed, err := deepseek.LastTurns{Turns: 2, Counter: countMessages}.Trim(testHistory(), 1000)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"system", "u2", "call", "call_1", "a2", "u3"}, contents(trimmed))
+
+	trimmed, err = deepseek.LastTurns{Counter: countMessages}.Trim(testHistory(), 1000)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"system", "u3"}, contents(trimmed))
+
+	trimmed, err = deepseek.LastTurns{Turns: 2, Counter: countMessages}.Trim(testHistory(), 40)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"system", "u3"}, contents(trimmed), "the budget still applies")
+}
+
+func TestDropToolResultsFirst(t *testing.T) {
+	strategy := deepseek.DropToolResultsFirst{Counter: countMessages}
+
+	trimmed, err := strategy.Trim(testHistory(), 60)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"system", "u1", "a1", "u2", "a2", "u3"}, contents(trimmed))
+
+	trimmed, err = strategy.Trim(testHistory(), 40)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"system", "u2", "a2", "u3"}, contents(trimmed), "the tool call is dropped before u2")
+
+	// Tool calls of the current turn are needed to answer it.
+	current := append(testHistory(), toolCallMessage("call_2"), toolResult("call_2"))
+	trimmed, err = strategy.Trim(current, 50)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"system", "u3", "call", "call_2"}, contents(trimmed))
+}
+
+func TestContextStrategy_OrphanedToolResults(t *testing.T) {
+	history := []deepseek.ChatCompletionMessage{
+		toolResult("call_0"),
+		message(deepseek.ChatMessageRoleUser, "u1"),
+	}
+	trimmed, err := deepseek.SlidingWindow{}.Trim(history, 1000)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u1"}, contents(trimmed))
+
+	trimmed, err = deepseek.SlidingWindow{}.Trim(nil, 1000)
+	require.NoError(t, err)
+	assert.Empty(t, trimmed)
+}
+
+func TestFitRequest(t *testing.T) {
+	maxTokens := 20
+	request := &deepseek.ChatCompletionRequest{Messages: testHistory(), MaxTokens: &maxTokens}
+	require.NoError(t, deepseek.FitRequest(request, deepseek.SlidingWindow{Counter: countMessages}, 60))
+	assert.Equal(t, []string{"system", "u3"}, contents(request.Messages), "max tokens are reserved")
+
+	request = &deepseek.ChatCompletionRequest{Messages: testHistory()}
+	require.NoError(t, deepseek.FitRequest(request, deepseek.SlidingWindow{}, 100000))
+	assert.Len(t, request.Messages, 8)
+}
+
+func TestConversation_Strategy(t *testing.T) {
+	srv, conv := newTestConversation(t, deepseektest.Response{Content: "a3"})
+	conv.Append(testHistory()[1:7]...)
+	conv.Strategy = deepseek.LastTurns{Turns: 1}
+	conv.ContextBudget = 64000
+
+	_, err := conv.Send(context.Background(), "u3")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Be brief.", "u3"}, contents(lastChatRequest(t, srv).Messages))
+	assert.Len(t, conv.Messages(), 9, "the history is kept in full")
+}
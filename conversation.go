@@ -0,0 +1,311 @@
+package deepseek
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Conversation owns the message history of a multi-turn chat and sends its turns through a client.
+//
+// Each turn sends the whole history and, if it succeeds, appends the new messages and the assistant's
+// reply, including its tool calls. A failed turn leaves the history unchanged, so it can be retried.
+// The reasoning content of earlier replies is kept in the history but stripped from requests, as the
+// reasoner API requires.
+//
+// A Conversation is safe for concurrent use, but turns should be sent one at a time.
+type Conversation struct {
+	Client CompletionClient // Client the turns are sent through, e.g. a *Client or a *FailoverClient.
+
+	// Template holds the parameters of every request, such as Model, Temperature or Tools.
+	// Its Messages are ignored.
+	Template ChatCompletionRequest
+
+	// Strategy, if set, trims the messages sent with each turn so that the request fits in ContextBudget
+	// tokens, e.g. SlidingWindow{}. The history itself is kept in full.
+	Strategy      ContextStrategy
+	ContextBudget int // Size of the model's context window in tokens. Strategy is only applied if > 0.
+
+	// Memory, if set, condenses the history before each turn, e.g. a *SummaryMemory. Unlike Strategy,
+	// it replaces the history itself.
+	Memory ConversationMemory
+
+	// Store, if set, records every turn under ID, including its model and usage. Restore a
+	// conversation from it with Load. A turn that cannot be recorded fails.
+	Store HistoryStore
+	ID    string // ID of the conversation in Store.
+
+	mu       sync.Mutex
+	messages []ChatCompletionMessage
+	unsaved  []ChatCompletionMessage // Messages appended since the last turn, recorded with the next one.
+}
+
+// NewConversation creates a conversation with model. If systemPrompt is not empty, it is the first message.
+func NewConversation(client CompletionClient, model string, systemPrompt string) *Conversation {
+	c := &Conversation{
+		Client:   client,
+		Template: ChatCompletionRequest{Model: model},
+	}
+	if systemPrompt != "" {
+		c.Append(ChatCompletionMessage{Role: ChatMessageRoleSystem, Content: systemPrompt})
+	}
+	return c
+}
+
+// Messages returns a copy of the history.
+func (c *Conversation) Messages() []ChatCompletionMessage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]ChatCompletionMessage(nil), c.messages...)
+}
+
+// Append adds messages to the history without sending them, e.g. earlier turns or tool results.
+func (c *Conversation) Append(messages ...ChatCompletionMessage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.messages = append(c.messages, messages...)
+	c.unsaved = append(c.unsaved, messages...)
+}
+
+// AddToolResult appends the result of the tool call with the given ID. Send it with Continue once
+// the results of all tool calls of the last reply were added.
+func (c *Conversation) AddToolResult(toolCallID, content string) {
+	c.Append(ChatCompletionMessage{Role: ChatMessageRoleTool, ToolCallID: toolCallID, Content: content})
+}
+
+// Reset clears the history, keeping the system prompt if there is one. The Store is left unchanged;
+// delete the conversation there to start it over.
+func (c *Conversation) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.unsaved) > 0 && c.unsaved[0].Role == ChatMessageRoleSystem {
+		c.unsaved = c.unsaved[:1]
+	} else {
+		c.unsaved = nil
+	}
+	if len(c.messages) > 0 && c.messages[0].Role == ChatMessageRoleSystem {
+		c.messages = c.messages[:1]
+		return
+	}
+	c.messages = nil
+}
+
+// Load replaces the history with the turns recorded under ID in Store. It returns ErrHistoryNotFound
+// if nothing was recorded yet, in which case the history is left unchanged.
+func (c *Conversation) Load(ctx context.Context) error {
+	if c.Store == nil {
+		return fmt.Errorf("conversation has no store")
+	}
+	turns, err := c.Store.Load(ctx, c.ID)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.messages = HistoryMessages(turns)
+	c.unsaved = nil
+	return nil
+}
+
+// Send sends a user message and returns the response. The reply of the first choice is appended to the history.
+func (c *Conversation) Send(ctx context.Context, content string) (*ChatCompletionResponse, error) {
+	return c.SendMessages(ctx, ChatCompletionMessage{Role: ChatMessageRoleUser, Content: content})
+}
+
+// Continue sends the history as it is, e.g. after AddToolResult.
+func (c *Conversation) Continue(ctx context.Context) (*ChatCompletionResponse, error) {
+	return c.SendMessages(ctx)
+}
+
+// SendMessages sends the history followed by messages. On success, messages and the reply of the
+// first choice are appended to the history.
+func (c *Conversation) SendMessages(ctx context.Context, messages ...ChatCompletionMessage) (*ChatCompletionResponse, error) {
+	if err := c.compact(ctx); err != nil {
+		return nil, err
+	}
+	request, turn, err := c.request(messages)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.Client.CreateChatCompletion(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.commit(ctx, turn, resp); err != nil {
+		return resp, err
+	}
+	return resp, nil
+}
+
+// SendStream sends a user message and returns the stream of the reply. Once the stream returns io.EOF,
+// the message and the reply of the first choice are appended to the history. If the stream fails or is
+// closed early, the history is left unchanged.
+func (c *Conversation) SendStream(ctx context.Context, content string) (ChatCompletionStream, error) {
+	return c.SendMessagesStream(ctx, ChatCompletionMessage{Role: ChatMessageRoleUser, Content: content})
+}
+
+// SendMessagesStream is like SendMessages, but returns the stream of the reply, see SendStream.
+func (c *Conversation) SendMessagesStream(ctx context.Context, messages ...ChatCompletionMessage) (ChatCompletionStream, error) {
+	if err := c.compact(ctx); err != nil {
+		return nil, err
+	}
+	request, turn, err := c.request(messages)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := c.Client.CreateChatCompletionStream(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return &conversationStream{ChatCompletionStream: stream, ctx: ctx, conversation: c, turn: turn, received: NewStreamAccumulator()}, nil
+}
+
+// Request returns the request the next turn would send, without sending it.
+func (c *Conversation) Request() (*ChatCompletionRequest, error) {
+	request, _, err := c.request(nil)
+	return request, err
+}
+
+// compact condenses the history with Memory. Messages appended while Memory runs are kept.
+func (c *Conversation) compact(ctx context.Context) error {
+	if c.Memory == nil {
+		return nil
+	}
+	history := c.Messages()
+	compacted, err := c.Memory.Compact(ctx, history)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.messages) < len(history) {
+		return nil // Reset while Memory ran.
+	}
+	c.messages = append(compacted, c.messages[len(history):]...)
+	return nil
+}
+
+// conversationTurn is a turn in flight.
+type conversationTurn struct {
+	history  []ChatCompletionMessage // History the request is based on, which the reply is committed to.
+	unsaved  []ChatCompletionMessage // Messages of the turn to record in the Store before the reply.
+	appended int                     // Number of messages appended before the turn, part of unsaved.
+}
+
+// request builds the request of a turn sending messages.
+func (c *Conversation) request(messages []ChatCompletionMessage) (*ChatCompletionRequest, *conversationTurn, error) {
+	c.mu.Lock()
+	history := make([]ChatCompletionMessage, 0, len(c.messages)+len(messages)+1)
+	history = append(history, c.messages...)
+	turn := &conversationTurn{
+		unsaved:  append(append([]ChatCompletionMessage(nil), c.unsaved...), messages...),
+		appended: len(c.unsaved),
+	}
+	c.mu.Unlock()
+	history = append(history, messages...)
+	turn.history = history
+
+	request := c.Template
+	request.Messages = requestMessages(history)
+	if c.Strategy != nil && c.ContextBudget > 0 {
+		if err := FitRequest(&request, c.Strategy, c.ContextBudget); err != nil {
+			return nil, nil, err
+		}
+	}
+	return &request, turn, nil
+}
+
+// requestMessages returns a copy of history without reasoning content, except on a final
+// prefix message, where Chat Prefix Completion accepts it.
+func requestMessages(history []ChatCompletionMessage) []ChatCompletionMessage {
+	messages := make([]ChatCompletionMessage, len(history))
+	for i, m := range history {
+		if !(m.Prefix && i == len(history)-1) {
+			m.ReasoningContent = ""
+		}
+		messages[i] = m
+	}
+	return messages
+}
+
+// commit records the turn in the Store, if any, and replaces the history with the history of the turn
+// followed by the reply of resp.
+func (c *Conversation) commit(ctx context.Context, turn *conversationTurn, resp *ChatCompletionResponse) error {
+	if len(resp.Choices) == 0 {
+		return fmt.Errorf("response has no choices")
+	}
+	reply := resp.Choices[0].Message
+	if reply.Role == "" {
+		reply.Role = ChatMessageRoleAssistant
+	}
+	history, unsaved := turn.history, turn.unsaved
+	// A prefix message is completed by the reply, which continues its content.
+	if n := len(history); n > 0 && history[n-1].Prefix {
+		prefix := history[n-1]
+		history = history[:n-1]
+		if m := len(unsaved); m > 0 && unsaved[m-1].Prefix {
+			unsaved = unsaved[:m-1]
+		}
+		reply.Content = prefix.Content + reply.Content
+		if reply.ReasoningContent == "" {
+			reply.ReasoningContent = prefix.ReasoningContent
+		}
+	}
+	message := ChatCompletionMessage{
+		Role:             reply.Role,
+		Content:          reply.Content,
+		ReasoningContent: reply.ReasoningContent,
+		ToolCalls:        reply.ToolCalls,
+	}
+	if c.Store != nil {
+		usage := resp.Usage
+		err := c.Store.Append(ctx, c.ID, HistoryTurn{
+			Messages:   append(unsaved, message),
+			Model:      resp.Model,
+			Usage:      &usage,
+			ResponseID: resp.ID,
+			CreatedAt:  time.Now().UTC(),
+		})
+		if err != nil {
+			return fmt.Errorf("recording turn: %w", err)
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.messages = append(history, message)
+	c.unsaved = c.unsaved[min(turn.appended, len(c.unsaved)):]
+	return nil
+}
+
+// conversationStream commits the reply of a streamed turn once the stream is complete.
+type conversationStream struct {
+	ChatCompletionStream
+	ctx          context.Context
+	conversation *Conversation
+	turn         *conversationTurn
+	received     *StreamAccumulator
+	committed    bool
+}
+
+// Recv receives the next chunk, and commits the turn when the stream ends.
+func (s *conversationStream) Recv() (*StreamChatCompletionResponse, error) {
+	chunk, err := s.ChatCompletionStream.Recv()
+	if errors.Is(err, io.EOF) && !s.committed {
+		s.committed = true
+		resp, accErr := s.received.Response()
+		if accErr != nil {
+			return nil, accErr
+		}
+		if commitErr := s.conversation.commit(s.ctx, s.turn, resp); commitErr != nil {
+			return nil, commitErr
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.received.Add(chunk)
+	return chunk, nil
+}
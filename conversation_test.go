@@ -0,0 +1,164 @@
+package deepseek_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/cohesion-org/deepseek-go"
+	"github.com/cohesion-org/deepseek-go/deepseektest"
+	"github.com/cohesion-org/deepseek-go/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestConversation(t *testing.T, responses ...deepseektest.Response) (*deepseektest.Server, *deepseek.Conversation) {
+	t.Helper()
+	srv, client := newTestServer(t)
+	srv.Enqueue(deepseektest.RouteChat, responses...)
+	return srv, deepseek.NewConversation(client, deepseek.DeepSeekReasoner, "Be brief.")
+}
+
+func lastChatRequest(t *testing.T, srv *deepseektest.Server) *deepseek.ChatCompletionRequest {
+	t.Helper()
+	recorded, ok := srv.LastRequest()
+	require.True(t, ok)
+	request, err := recorded.ChatRequest()
+	require.NoError(t, err)
+	return request
+}
+
+func TestConversation_Send(t *testing.T) {
+	srv, conv := newTestConversation(t,
+		deepseektest.Response{Content: "Everest.", ReasoningContent: "Tallest mountain."},
+		deepseektest.Response{Content: "K2."},
+	)
+	conv.Template.Temperature = utils.Float32Ptr(0.5)
+
+	resp, err := conv.Send(context.Background(), "Tallest mountain?")
+	require.NoError(t, err)
+	assert.Equal(t, "Everest.", resp.Choices[0].Message.Content)
+
+	_, err = conv.Send(context.Background(), "And the second?")
+	require.NoError(t, err)
+
+	sent := lastChatRequest(t, srv)
+	assert.Equal(t, deepseek.DeepSeekReasoner, sent.Model)
+	require.NotNil(t, sent.Temperature)
+	assert.Equal(t, float32(0.5), *sent.Temperature)
+	require.Len(t, sent.Messages, 4)
+	assert.Equal(t, deepseek.ChatMessageRoleSystem, sent.Messages[0].Role)
+	assert.Equal(t, "Everest.", sent.Messages[2].Content)
+	assert.Empty(t, sent.Messages[2].ReasoningContent, "reasoning content is not sent back")
+
+	history := conv.Messages()
+	require.Len(t, history, 5)
+	assert.Equal(t, "Tallest mountain.", history[2].ReasoningContent, "but it is kept in the history")
+	assert.Equal(t, "K2.", history[4].Content)
+}
+
+func TestConversation_FailedTurnIsNotRecorded(t *testing.T) {
+	_, conv := newTestConversation(t,
+		deepseektest.Response{Status: http.StatusServiceUnavailable},
+		deepseektest.Response{Content: "Everest."},
+	)
+
+	_, err := conv.Send(context.Background(), "Tallest mountain?")
+	require.Error(t, err)
+	assert.Len(t, conv.Messages(), 1)
+
+	_, err = conv.Send(context.Background(), "Tallest mountain?")
+	require.NoError(t, err)
+	assert.Len(t, conv.Messages(), 3)
+}
+
+func TestConversation_ToolCalls(t *testing.T) {
+	name := "get_weather"
+	srv, conv := newTestConversation(t,
+		deepseektest.Response{ToolCalls: []deepseek.ToolCall{{Function: deepseek.ToolCallFunction{Name: &name, Arguments: `{"city":"Paris"}`}}}},
+		deepseektest.Response{Content: "It is sunny in Paris."},
+	)
+
+	resp, err := conv.Send(context.Background(), "Weather in Paris?")
+	require.NoError(t, err)
+	calls := resp.Choices[0].Message.ToolCalls
+	require.Len(t, calls, 1)
+	require.NotNil(t, calls[0].ID)
+
+	conv.AddToolResult(*calls[0].ID, `{"weather":"sunny"}`)
+	resp, err = conv.Continue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "It is sunny in Paris.", resp.Choices[0].Message.Content)
+
+	sent := lastChatRequest(t, srv)
+	require.Len(t, sent.Messages, 4)
+	assistant := sent.Messages[2]
+	assert.Equal(t, deepseek.ChatMessageRoleAssistant, assistant.Role)
+	assert.Empty(t, assistant.Content)
+	require.Len(t, assistant.ToolCalls, 1)
+	assert.Equal(t, *calls[0].ID, *assistant.ToolCalls[0].ID)
+	assert.Equal(t, deepseek.ChatMessageRoleTool, sent.Messages[3].Role)
+	assert.Equal(t, *calls[0].ID, sent.Messages[3].ToolCallID)
+}
+
+func TestConversation_SendStream(t *testing.T) {
+	srv, conv := newTestConversation(t,
+		deepseektest.Response{Content: "Everest.", ReasoningContent: "Easy."},
+		deepseektest.Response{Content: "partial", OmitDone: true},
+		deepseektest.Response{Content: "K2."},
+	)
+
+	stream, err := conv.SendStream(context.Background(), "Tallest mountain?")
+	require.NoError(t, err)
+	resp, err := deepseek.AccumulateStream(stream)
+	require.NoError(t, err)
+	require.NoError(t, stream.Close())
+	assert.Equal(t, "Everest.", resp.Choices[0].Message.Content)
+	history := conv.Messages()
+	require.Len(t, history, 3)
+	assert.Equal(t, "Everest.", history[2].Content)
+	assert.Equal(t, "Easy.", history[2].ReasoningContent)
+
+	stream, err = conv.SendStream(context.Background(), "And the second?")
+	require.NoError(t, err)
+	_, err = deepseek.AccumulateStream(stream)
+	require.ErrorIs(t, err, deepseek.ErrStreamTruncated)
+	require.NoError(t, stream.Close())
+	assert.Len(t, conv.Messages(), 3, "a failed stream is not recorded")
+
+	stream, err = conv.SendStream(context.Background(), "And the second?")
+	require.NoError(t, err)
+	_, err = deepseek.AccumulateStream(stream)
+	require.NoError(t, err)
+	require.NoError(t, stream.Close())
+	assert.Len(t, conv.Messages(), 5)
+	assert.Len(t, lastChatRequest(t, srv).Messages, 4)
+}
+
+func TestConversation_Reset(t *testing.T) {
+	_, conv := newTestConversation(t)
+	conv.Append(deepseek.ChatCompletionMessage{Role: deepseek.ChatMessageRoleUser, Content: "Hi"})
+	conv.Reset()
+	require.Len(t, conv.Messages(), 1)
+	assert.Equal(t, "Be brief.", conv.Messages()[0].Content)
+	request, err := conv.Request()
+	require.NoError(t, err)
+	assert.Len(t, request.Messages, 1)
+}
+
+func TestMapMessageToChatCompletionMessage(t *testing.T) {
+	id, name := "call_1", "get_weather"
+	message, err := deepseek.MapMessageToChatCompletionMessage(deepseek.Message{
+		Role:             deepseek.ChatMessageRoleAssistant,
+		ReasoningContent: "Need the weather.",
+		ToolCalls:        []deepseek.ToolCall{{ID: &id, Function: deepseek.ToolCallFunction{Name: &name, Arguments: "{}"}}},
+	})
+	require.NoError(t, err)
+	assert.Len(t, message.ToolCalls, 1)
+	assert.Empty(t, message.ReasoningContent)
+
+	_, err = deepseek.MapMessageToChatCompletionMessage(deepseek.Message{Role: deepseek.ChatMessageRoleAssistant})
+	assert.Error(t, err)
+	_, err = deepseek.MapMessageToChatCompletionMessage(deepseek.Message{Role: "robot", Content: "beep"})
+	assert.ErrorContains(t, err, "invalid role: robot")
+}
@@ -0,0 +1,306 @@
+package deepseektest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	deepseek "github.com/cohesion-org/deepseek-go"
+)
+
+// CassetteVersion is the version of the on-disk cassette format.
+const CassetteVersion = 1
+
+// Mode selects whether a Recorder records real exchanges or replays recorded ones.
+type Mode int
+
+const (
+	// ModeReplay serves responses from the cassette and never touches the network.
+	ModeReplay Mode = iota
+	// ModeRecord sends requests to the real API and stores the exchanges in the cassette.
+	ModeRecord
+	// ModeAuto replays if the cassette file exists and records otherwise.
+	ModeAuto
+)
+
+// ErrNoInteraction is returned in replay mode when no recorded interaction matches a request.
+var ErrNoInteraction = errors.New("deepseektest: no recorded interaction matches the request")
+
+// DefaultScrubbedHeaders are the request headers never written to a cassette.
+var DefaultScrubbedHeaders = []string{"Authorization", "Api-Key", "X-Api-Key", "Cookie"}
+
+// Cassette is the on-disk record of request/response pairs.
+type Cassette struct {
+	Version      int           `json:"version"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a single recorded request/response pair.
+type Interaction struct {
+	Request  CassetteRequest  `json:"request"`
+	Response CassetteResponse `json:"response"`
+}
+
+// CassetteRequest is the recorded part of a request.
+type CassetteRequest struct {
+	Method string      `json:"method"`
+	Path   string      `json:"path"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// CassetteResponse is the recorded part of a response. SSE streams are stored verbatim in Body.
+type CassetteResponse struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body"`
+}
+
+// Recorder is an HTTPDoer that records exchanges to a cassette file or replays them.
+// Pass it to deepseek.WithHTTPClient. Call Save once done recording.
+//
+// Requests are matched by method, path and normalized JSON body. Each recorded
+// interaction is replayed once, in order; the last match is reused when all
+// matching interactions have been consumed.
+type Recorder struct {
+	ScrubHeaders []string // Request headers removed before writing. Defaults to DefaultScrubbedHeaders.
+
+	mode     Mode
+	file     string
+	next     deepseek.HTTPDoer
+	mu       sync.Mutex
+	cassette Cassette
+	used     []bool
+	dirty    bool
+}
+
+// NewRecorder creates a Recorder for the cassette file. In record mode requests are
+// sent through next, which defaults to http.DefaultClient.
+func NewRecorder(file string, mode Mode, next deepseek.HTTPDoer) (*Recorder, error) {
+	if next == nil {
+		next = http.DefaultClient
+	}
+	r := &Recorder{mode: mode, file: file, next: next}
+
+	data, err := os.ReadFile(file)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &r.cassette); err != nil {
+			return nil, fmt.Errorf("error decoding cassette %s: %w", file, err)
+		}
+		if r.cassette.Version > CassetteVersion {
+			return nil, fmt.Errorf("cassette %s has unsupported version %d", file, r.cassette.Version)
+		}
+	case errors.Is(err, os.ErrNotExist):
+		if mode == ModeReplay {
+			return nil, fmt.Errorf("cassette %s not found: %w", file, err)
+		}
+	default:
+		return nil, fmt.Errorf("error reading cassette %s: %w", file, err)
+	}
+
+	if r.mode == ModeAuto {
+		r.mode = ModeReplay
+		if err != nil {
+			r.mode = ModeRecord
+		}
+	}
+	if r.mode == ModeRecord {
+		// Recording always starts from a fresh cassette.
+		r.cassette = Cassette{}
+	}
+	r.cassette.Version = CassetteVersion
+	r.used = make([]bool, len(r.cassette.Interactions))
+	return r, nil
+}
+
+// Mode returns the effective mode of the recorder. ModeAuto is resolved at creation.
+func (r *Recorder) Mode() Mode {
+	return r.mode
+}
+
+// Do records or replays a single request.
+func (r *Recorder) Do(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	if r.mode == ModeReplay {
+		return r.replay(req, body)
+	}
+	return r.record(req, body)
+}
+
+// Save writes the cassette to disk if anything was recorded.
+func (r *Recorder) Save() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.mode != ModeRecord || !r.dirty {
+		return nil
+	}
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding cassette: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(r.file), 0o755); err != nil {
+		return fmt.Errorf("error creating cassette directory: %w", err)
+	}
+	if err := os.WriteFile(r.file, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("error writing cassette: %w", err)
+	}
+	r.dirty = false
+	return nil
+}
+
+func (r *Recorder) replay(req *http.Request, body []byte) (*http.Response, error) {
+	key := matchKey(req.Method, req.URL.Path, body)
+
+	r.mu.Lock()
+	found := -1
+	for i, interaction := range r.cassette.Interactions {
+		ir := interaction.Request
+		if matchKey(ir.Method, ir.Path, []byte(ir.Body)) != key {
+			continue
+		}
+		found = i
+		if !r.used[i] {
+			break
+		}
+	}
+	if found >= 0 {
+		r.used[found] = true
+	}
+	r.mu.Unlock()
+
+	if found < 0 {
+		return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, req.Method, req.URL.Path)
+	}
+	recorded := r.cassette.Interactions[found].Response
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.Status, http.StatusText(recorded.Status)),
+		StatusCode:    recorded.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        recorded.Header.Clone(),
+		Body:          io.NopCloser(strings.NewReader(recorded.Body)),
+		ContentLength: int64(len(recorded.Body)),
+		Request:       req,
+	}, nil
+}
+
+func (r *Recorder) record(req *http.Request, body []byte) (*http.Response, error) {
+	resp, err := r.next.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	interaction := Interaction{
+		Request: CassetteRequest{
+			Method: req.Method,
+			Path:   req.URL.Path,
+			Header: r.scrub(req.Header),
+			Body:   string(body),
+		},
+		Response: CassetteResponse{
+			Status: resp.StatusCode,
+			Header: resp.Header.Clone(),
+		},
+	}
+	// Capture the body as the caller reads it, so streams are still delivered incrementally.
+	resp.Body = &recordingBody{
+		ReadCloser: resp.Body,
+		done: func(captured []byte) {
+			interaction.Response.Body = string(captured)
+			r.mu.Lock()
+			r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+			r.used = append(r.used, false)
+			r.dirty = true
+			r.mu.Unlock()
+		},
+	}
+	return resp, nil
+}
+
+func (r *Recorder) scrub(header http.Header) http.Header {
+	scrubbed := header.Clone()
+	names := r.ScrubHeaders
+	if names == nil {
+		names = DefaultScrubbedHeaders
+	}
+	for _, name := range names {
+		scrubbed.Del(name)
+	}
+	return scrubbed
+}
+
+// recordingBody copies everything read from the body and reports it once, on EOF or Close.
+type recordingBody struct {
+	io.ReadCloser
+	buf  bytes.Buffer
+	done func([]byte)
+	once sync.Once
+}
+
+func (b *recordingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.buf.Write(p[:n])
+	if err == io.EOF {
+		b.finish()
+	}
+	return n, err
+}
+
+func (b *recordingBody) Close() error {
+	// Keep whatever the caller did not read, so the cassette holds the full response.
+	_, _ = io.Copy(&b.buf, b.ReadCloser)
+	b.finish()
+	return b.ReadCloser.Close()
+}
+
+func (b *recordingBody) finish() {
+	b.once.Do(func() { b.done(b.buf.Bytes()) })
+}
+
+// readRequestBody reads the request body and restores it so the request can still be sent.
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading request body: %w", err)
+	}
+	_ = req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// matchKey builds the key used to match requests: method, cleaned path and normalized JSON body.
+func matchKey(method, p string, body []byte) string {
+	return method + " " + path.Clean("/"+p) + " " + normalizeJSON(body)
+}
+
+// normalizeJSON re-encodes JSON so that key order and whitespace do not matter.
+func normalizeJSON(body []byte) string {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return ""
+	}
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return string(body)
+	}
+	normalized, err := json.Marshal(v)
+	if err != nil {
+		return string(body)
+	}
+	return string(normalized)
+}
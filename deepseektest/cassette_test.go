@@ -0,0 +1,172 @@
+package deepseektest_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	deepseek "github.com/cohesion-org/deepseek-go"
+	"github.com/cohesion-org/deepseek-go/deepseektest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readStream(t *testing.T, stream deepseek.ChatCompletionStream) string {
+	t.Helper()
+	var content string
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return content
+		}
+		require.NoError(t, err)
+		for _, choice := range chunk.Choices {
+			if choice.Delta.Content != nil {
+				content += *choice.Delta.Content
+			}
+		}
+	}
+}
+
+func TestRecorder_RecordAndReplay(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "cassettes", "chat.json")
+
+	srv := deepseektest.NewServer()
+	srv.Enqueue(deepseektest.RouteChat,
+		deepseektest.Response{Content: "Recorded answer"},
+		deepseektest.Response{Content: "Recorded stream answer"},
+	)
+
+	// Record.
+	recorder, err := deepseektest.NewRecorder(file, deepseektest.ModeRecord, srv.HTTPDoer())
+	require.NoError(t, err)
+	client, err := deepseek.NewClientWithOptions("sk-secret",
+		deepseek.WithBaseURL("https://api.deepseek.com/"),
+		deepseek.WithHTTPClient(recorder),
+	)
+	require.NoError(t, err)
+
+	resp, err := client.CreateChatCompletion(context.Background(), newChatRequest())
+	require.NoError(t, err)
+	assert.Equal(t, "Recorded answer", resp.Choices[0].Message.Content)
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), newChatRequest())
+	require.NoError(t, err)
+	assert.Equal(t, "Recorded stream answer", readStream(t, stream))
+	require.NoError(t, stream.Close())
+	require.NoError(t, recorder.Save())
+	srv.Close()
+
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "sk-secret", "the API key must be scrubbed")
+	assert.Contains(t, string(data), "data: [DONE]", "SSE streams are stored verbatim")
+
+	// Replay without any server.
+	replayer, err := deepseektest.NewRecorder(file, deepseektest.ModeReplay, nil)
+	require.NoError(t, err)
+	client, err = deepseek.NewClientWithOptions("another-key",
+		deepseek.WithBaseURL("https://api.deepseek.com/"),
+		deepseek.WithHTTPClient(replayer),
+	)
+	require.NoError(t, err)
+
+	resp, err = client.CreateChatCompletion(context.Background(), newChatRequest())
+	require.NoError(t, err)
+	assert.Equal(t, "Recorded answer", resp.Choices[0].Message.Content)
+
+	stream, err = client.CreateChatCompletionStream(context.Background(), newChatRequest())
+	require.NoError(t, err)
+	defer stream.Close()
+	assert.Equal(t, "Recorded stream answer", readStream(t, stream))
+}
+
+func TestRecorder_MatchesNormalizedJSON(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "cassette.json")
+	cassette := `{
+		"version": 1,
+		"interactions": [{
+			"request": {"method": "POST", "path": "/chat/completions", "body": "{\"b\": 2, \"a\": 1}"},
+			"response": {"status": 200, "body": "first"}
+		}, {
+			"request": {"method": "POST", "path": "/chat/completions", "body": "{\"a\": 1, \"b\": 2}"},
+			"response": {"status": 200, "body": "second"}
+		}]
+	}`
+	require.NoError(t, os.WriteFile(file, []byte(cassette), 0o644))
+
+	recorder, err := deepseektest.NewRecorder(file, deepseektest.ModeAuto, nil)
+	require.NoError(t, err)
+	assert.Equal(t, deepseektest.ModeReplay, recorder.Mode())
+
+	send := func(body string) (string, error) {
+		req, err := http.NewRequest(http.MethodPost, "https://example.com/chat/completions", bytes.NewReader([]byte(body)))
+		require.NoError(t, err)
+		resp, err := recorder.Do(req)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return string(data), nil
+	}
+
+	// Interactions are consumed in order, the last match is reused afterwards.
+	for _, want := range []string{"first", "second", "second"} {
+		got, err := send(`{"a":1,"b":2}`)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+
+	_, err = send(`{"a":2}`)
+	assert.ErrorIs(t, err, deepseektest.ErrNoInteraction)
+}
+
+func TestRecorder_MissingCassette(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "missing.json")
+
+	_, err := deepseektest.NewRecorder(file, deepseektest.ModeReplay, nil)
+	assert.Error(t, err)
+
+	recorder, err := deepseektest.NewRecorder(file, deepseektest.ModeAuto, nil)
+	require.NoError(t, err)
+	assert.Equal(t, deepseektest.ModeRecord, recorder.Mode())
+
+	// Nothing recorded, nothing written.
+	require.NoError(t, recorder.Save())
+	_, err = os.Stat(file)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestRecorder_ScrubsCustomHeaders(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "cassette.json")
+	srv := deepseektest.NewServer()
+	defer srv.Close()
+
+	recorder, err := deepseektest.NewRecorder(file, deepseektest.ModeRecord, srv.HTTPDoer())
+	require.NoError(t, err)
+	recorder.ScrubHeaders = append(deepseektest.DefaultScrubbedHeaders, "X-Session")
+
+	req, err := http.NewRequest(http.MethodGet, "https://api.deepseek.com/models", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("X-Session", "private")
+	req.Header.Set("X-Visible", "public")
+	resp, err := recorder.Do(req)
+	require.NoError(t, err)
+	_, _ = io.Copy(io.Discard, resp.Body)
+	require.NoError(t, resp.Body.Close())
+	require.NoError(t, recorder.Save())
+
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.False(t, strings.Contains(string(data), "secret") || strings.Contains(string(data), "private"))
+	assert.Contains(t, string(data), "public")
+}
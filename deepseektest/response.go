@@ -0,0 +1,388 @@
+package deepseektest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	deepseek "github.com/cohesion-org/deepseek-go"
+)
+
+// ErrorBody is the error object returned by the API, served as {"error": {...}}.
+type ErrorBody struct {
+	Message string `json:"message"`         // Human-readable error message.
+	Type    string `json:"type,omitempty"`  // Error type, e.g. "invalid_request_error".
+	Param   string `json:"param,omitempty"` // Request parameter the error relates to.
+	Code    string `json:"code,omitempty"`  // Machine-readable error code.
+}
+
+type errorEnvelope struct {
+	Error ErrorBody `json:"error"`
+}
+
+// Response scripts a single reply of the Server.
+//
+// For chat and FIM routes the reply is rendered as a regular completion or as an SSE stream,
+// depending on the "stream" field of the request. For the balance and models routes only
+// Status, Header, Latency, Error and Body are used.
+type Response struct {
+	Status  int               // HTTP status code. Defaults to 200, or 500 if Error is set.
+	Header  map[string]string // Extra response headers, e.g. "Retry-After".
+	Latency time.Duration     // Delay before the response is written.
+	Error   *ErrorBody        // If set, the request fails with this error body.
+	Body    json.RawMessage   // If set, served verbatim for non-streaming requests.
+
+	ID               string                // Completion ID. Defaults to "fake-<unix nano>".
+	Model            string                // Model name. Defaults to deepseek.DeepSeekChat.
+	Content          string                // Assistant content, or the completion text for FIM.
+	ReasoningContent string                // Reasoning content (chat only).
+	ToolCalls        []deepseek.ToolCall   // Tool calls (chat only). Missing IDs and types are filled in.
+	FinishReason     deepseek.FinishReason // Defaults to "tool_calls" with tool calls, "stop" otherwise.
+	Usage            *deepseek.Usage       // Token usage. Estimated from the content if nil.
+
+	Chunks       []string      // Content split into stream chunks. Defaults to splitting Content after spaces.
+	ChunkDelay   time.Duration // Delay between stream chunks.
+	StreamError  *ErrorBody    // If set, sent as an in-band error event after the content chunks.
+	OmitDone     bool          // If true, the stream ends without the final "data: [DONE]".
+	OmitUsage    bool          // If true, no usage is sent in the final stream chunk.
+	KeepAlive    bool          // If true, ": keep-alive" comments are sent between chunks.
+	RawStreamEnd string        // If set, written verbatim after the content chunks, before [DONE].
+}
+
+func (r Response) status() int {
+	if r.Status != 0 {
+		return r.Status
+	}
+	if r.Error != nil {
+		return http.StatusInternalServerError
+	}
+	return http.StatusOK
+}
+
+func (r Response) bodyOr(v interface{}) interface{} {
+	if r.Body != nil {
+		return r.Body
+	}
+	return v
+}
+
+func (r Response) writeError(w http.ResponseWriter) {
+	body := r.Error
+	if body == nil {
+		body = &ErrorBody{Message: http.StatusText(r.status())}
+	}
+	if r.Body != nil {
+		writeJSON(w, r.status(), r.Body)
+		return
+	}
+	writeJSON(w, r.status(), errorEnvelope{*body})
+}
+
+func (r Response) id() string {
+	if r.ID != "" {
+		return r.ID
+	}
+	return fmt.Sprintf("fake-%d", time.Now().UnixNano())
+}
+
+func (r Response) model() string {
+	if r.Model != "" {
+		return r.Model
+	}
+	return deepseek.DeepSeekChat
+}
+
+func (r Response) finishReason() deepseek.FinishReason {
+	if r.FinishReason != "" {
+		return r.FinishReason
+	}
+	if len(r.ToolCalls) > 0 {
+		return deepseek.FinishReasonToolCalls
+	}
+	return deepseek.FinishReasonStop
+}
+
+func (r Response) usage() deepseek.Usage {
+	if r.Usage != nil {
+		return *r.Usage
+	}
+	completion := deepseek.EstimateTokenCount(r.ReasoningContent + r.Content).EstimatedTokens
+	usage := deepseek.Usage{
+		PromptTokens:          10,
+		CompletionTokens:      completion,
+		TotalTokens:           10 + completion,
+		PromptCacheMissTokens: 10,
+	}
+	if r.ReasoningContent != "" {
+		usage.CompletionTokensDetails = &deepseek.CompletionTokensDetails{
+			ReasoningTokens: deepseek.EstimateTokenCount(r.ReasoningContent).EstimatedTokens,
+		}
+	}
+	return usage
+}
+
+// toolCalls returns the tool calls with indexes, IDs and types filled in.
+func (r Response) toolCalls() []deepseek.ToolCall {
+	calls := make([]deepseek.ToolCall, len(r.ToolCalls))
+	for i, call := range r.ToolCalls {
+		call.Index = i
+		if call.ID == nil {
+			id := fmt.Sprintf("call_%d", i)
+			call.ID = &id
+		}
+		if call.Type == nil {
+			typ := "function"
+			call.Type = &typ
+		}
+		calls[i] = call
+	}
+	return calls
+}
+
+func (r Response) chunks() []string {
+	if r.Chunks != nil {
+		return r.Chunks
+	}
+	return splitChunks(r.Content)
+}
+
+func (r Response) chatCompletion() deepseek.ChatCompletionResponse {
+	return deepseek.ChatCompletionResponse{
+		ID:      r.id(),
+		Object:  "chat.completion",
+		Created: time.Now().Unix(),
+		Model:   r.model(),
+		Choices: []deepseek.Choice{{
+			Index: 0,
+			Message: deepseek.Message{
+				Role:             deepseek.ChatMessageRoleAssistant,
+				Content:          r.Content,
+				ReasoningContent: r.ReasoningContent,
+				ToolCalls:        r.toolCalls(),
+			},
+			FinishReason: r.finishReason(),
+		}},
+		Usage: r.usage(),
+	}
+}
+
+func (r Response) fimCompletion() map[string]interface{} {
+	usage := r.usage()
+	return map[string]interface{}{
+		"id":      r.id(),
+		"object":  "text_completion",
+		"created": time.Now().Unix(),
+		"model":   r.model(),
+		"choices": []map[string]interface{}{{
+			"text":          r.Content,
+			"index":         0,
+			"logprobs":      map[string]interface{}{"content": nil},
+			"finish_reason": r.finishReason(),
+		}},
+		"usage": usage,
+	}
+}
+
+// writeChatStream writes the response as chat completion chunks.
+func (r Response) writeChatStream(ctx context.Context, w http.ResponseWriter) {
+	sw := newStreamWriter(ctx, w, r)
+	id, model, created := r.id(), r.model(), time.Now().Unix()
+	chunk := func(delta deepseek.StreamDelta, finishReason *deepseek.FinishReason, usage *deepseek.Usage) deepseek.StreamChatCompletionResponse {
+		return deepseek.StreamChatCompletionResponse{
+			ID:      id,
+			Object:  "chat.completion.chunk",
+			Created: created,
+			Model:   model,
+			Choices: []deepseek.StreamChoices{{Index: 0, Delta: delta, FinishReason: finishReason}},
+			Usage:   usage,
+		}
+	}
+
+	role, empty := deepseek.ChatMessageRoleAssistant, ""
+	if !sw.event(chunk(deepseek.StreamDelta{Role: &role, Content: &empty}, nil, nil)) {
+		return
+	}
+	for _, part := range splitChunks(r.ReasoningContent) {
+		part := part
+		if !sw.event(chunk(deepseek.StreamDelta{ReasoningContent: &part}, nil, nil)) {
+			return
+		}
+	}
+	for _, part := range r.chunks() {
+		part := part
+		if !sw.event(chunk(deepseek.StreamDelta{Content: &part}, nil, nil)) {
+			return
+		}
+	}
+	for _, call := range r.toolCalls() {
+		// The first fragment carries the ID, type and name; the arguments follow in pieces.
+		args := call.Function.Arguments
+		head := call
+		head.Function.Arguments = ""
+		if !sw.event(chunk(deepseek.StreamDelta{ToolCalls: []deepseek.ToolCall{head}}, nil, nil)) {
+			return
+		}
+		for _, part := range splitHalves(args) {
+			fragment := deepseek.ToolCall{Index: call.Index, Function: deepseek.ToolCallFunction{Arguments: part}}
+			if !sw.event(chunk(deepseek.StreamDelta{ToolCalls: []deepseek.ToolCall{fragment}}, nil, nil)) {
+				return
+			}
+		}
+	}
+	sw.finish(func() interface{} {
+		finishReason := r.finishReason()
+		var usage *deepseek.Usage
+		if !r.OmitUsage {
+			u := r.usage()
+			usage = &u
+		}
+		return chunk(deepseek.StreamDelta{Content: &empty}, &finishReason, usage)
+	})
+}
+
+// writeFIMStream writes the response as FIM completion chunks.
+func (r Response) writeFIMStream(ctx context.Context, w http.ResponseWriter) {
+	sw := newStreamWriter(ctx, w, r)
+	id, model, created := r.id(), r.model(), time.Now().Unix()
+	chunk := func(text string, finishReason interface{}, usage *deepseek.Usage) map[string]interface{} {
+		c := map[string]interface{}{
+			"id":                 id,
+			"object":             "text_completion",
+			"created":            created,
+			"model":              model,
+			"system_fingerprint": "fp_fake",
+			"choices": []map[string]interface{}{{
+				"text":          text,
+				"index":         0,
+				"logprobs":      nil,
+				"finish_reason": finishReason,
+			}},
+		}
+		if usage != nil {
+			c["usage"] = usage
+		}
+		return c
+	}
+
+	for _, part := range r.chunks() {
+		if !sw.event(chunk(part, nil, nil)) {
+			return
+		}
+	}
+	sw.finish(func() interface{} {
+		var usage *deepseek.Usage
+		if !r.OmitUsage {
+			u := r.usage()
+			usage = &u
+		}
+		return chunk("", r.finishReason(), usage)
+	})
+}
+
+// streamWriter writes server-sent events, flushing after each one.
+type streamWriter struct {
+	ctx     context.Context
+	w       http.ResponseWriter
+	flusher http.Flusher
+	resp    Response
+	sent    int
+}
+
+func newStreamWriter(ctx context.Context, w http.ResponseWriter, resp Response) *streamWriter {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(resp.status())
+	flusher, _ := w.(http.Flusher)
+	return &streamWriter{ctx: ctx, w: w, flusher: flusher, resp: resp}
+}
+
+// event writes one data event. It returns false if the client went away.
+func (sw *streamWriter) event(v interface{}) bool {
+	if sw.sent > 0 {
+		if !sleep(sw.ctx, sw.resp.ChunkDelay) {
+			return false
+		}
+		if sw.resp.KeepAlive {
+			sw.write(": keep-alive\n\n")
+		}
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return false
+	}
+	sw.sent++
+	return sw.write("data: " + string(data) + "\n\n")
+}
+
+// finish writes the final chunk, the optional in-band error, and the [DONE] marker.
+func (sw *streamWriter) finish(final func() interface{}) {
+	if sw.resp.StreamError != nil {
+		data, _ := json.Marshal(errorEnvelope{*sw.resp.StreamError})
+		sw.write("data: " + string(data) + "\n\n")
+		return
+	}
+	if !sw.event(final()) {
+		return
+	}
+	if sw.resp.RawStreamEnd != "" {
+		sw.write(sw.resp.RawStreamEnd)
+	}
+	if !sw.resp.OmitDone {
+		sw.write("data: [DONE]\n\n")
+	}
+}
+
+func (sw *streamWriter) write(s string) bool {
+	if sw.ctx.Err() != nil {
+		return false
+	}
+	if _, err := sw.w.Write([]byte(s)); err != nil {
+		return false
+	}
+	if sw.flusher != nil {
+		sw.flusher.Flush()
+	}
+	return true
+}
+
+// splitChunks splits s after every space, the way tokens usually arrive.
+func splitChunks(s string) []string {
+	var chunks []string
+	for _, part := range strings.SplitAfter(s, " ") {
+		if part != "" {
+			chunks = append(chunks, part)
+		}
+	}
+	return chunks
+}
+
+// splitHalves splits s in two non-empty parts when possible.
+func splitHalves(s string) []string {
+	runes := []rune(s)
+	switch len(runes) {
+	case 0:
+		return nil
+	case 1:
+		return []string{s}
+	}
+	mid := len(runes) / 2
+	return []string{string(runes[:mid]), string(runes[mid:])}
+}
+
+// sleep waits for d, returning false if ctx is done first.
+func sleep(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,299 @@
+// Package deepseektest provides an in-process fake of the DeepSeek API for tests.
+//
+// The fake server emulates the chat completion (streaming and non-streaming), FIM, balance and
+// models endpoints. Responses can be scripted per endpoint, including tool calls, reasoning content,
+// injected errors and latency, and every received request is recorded for assertions.
+//
+//	srv := deepseektest.NewServer()
+//	defer srv.Close()
+//	srv.Enqueue(deepseektest.RouteChat, deepseektest.Response{Content: "Hi!"})
+//	client, _ := srv.Client()
+//	resp, _ := client.CreateChatCompletion(ctx, request)
+package deepseektest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+
+	deepseek "github.com/cohesion-org/deepseek-go"
+)
+
+// Route identifies an endpoint emulated by the Server.
+type Route string
+
+// Routes served by the Server.
+const (
+	RouteChat    Route = "/chat/completions"
+	RouteFIM     Route = "/beta/completions"
+	RouteBalance Route = "/user/balance"
+	RouteModels  Route = "/models"
+)
+
+// RecordedRequest is a request received by the Server.
+type RecordedRequest struct {
+	Route  Route       // The route that served the request, empty if no route matched.
+	Method string      // HTTP method.
+	Path   string      // URL path as received.
+	Query  url.Values  // URL query parameters.
+	Header http.Header // Request headers.
+	Body   []byte      // Raw request body.
+}
+
+// ChatRequest decodes the body as a chat completion request.
+func (r RecordedRequest) ChatRequest() (*deepseek.ChatCompletionRequest, error) {
+	var req deepseek.ChatCompletionRequest
+	if err := json.Unmarshal(r.Body, &req); err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+// FIMRequest decodes the body as a (streaming or non-streaming) FIM completion request.
+func (r RecordedRequest) FIMRequest() (*deepseek.FIMStreamCompletionRequest, error) {
+	var req deepseek.FIMStreamCompletionRequest
+	if err := json.Unmarshal(r.Body, &req); err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+// Server is an in-process fake DeepSeek API server.
+// Its exported methods are safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	queues   map[Route][]Response
+	defaults map[Route]Response
+	requests []RecordedRequest
+	balance  deepseek.BalanceResponse
+	models   deepseek.APIModels
+}
+
+// NewServer starts a fake DeepSeek API server. Call Close when done.
+func NewServer() *Server {
+	s := &Server{
+		queues: make(map[Route][]Response),
+		defaults: map[Route]Response{
+			RouteChat: {Content: "Hello! This is a fake DeepSeek response."},
+			RouteFIM:  {Content: "return a + b"},
+		},
+		balance: deepseek.BalanceResponse{
+			IsAvailable: true,
+			BalanceInfos: []deepseek.BalanceInfo{{
+				Currency:        "USD",
+				TotalBalance:    "10.00",
+				GrantedBalance:  "0.00",
+				ToppedUpBalance: "10.00",
+			}},
+		},
+		models: deepseek.APIModels{
+			Object: "list",
+			Data: []deepseek.Model{
+				{ID: deepseek.DeepSeekChat, Object: "model", OwnedBy: "deepseek"},
+				{ID: deepseek.DeepSeekReasoner, Object: "model", OwnedBy: "deepseek"},
+			},
+		},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// BaseURL returns the base URL to configure on a deepseek.Client, with a trailing slash.
+func (s *Server) BaseURL() string {
+	return s.URL + "/"
+}
+
+// Client returns a deepseek.Client whose requests are all routed to the fake server.
+// Additional options are applied after the defaults.
+func (s *Server) Client(opts ...deepseek.Option) (*deepseek.Client, error) {
+	defaults := []deepseek.Option{
+		deepseek.WithBaseURL(s.BaseURL()),
+		deepseek.WithHTTPClient(s.HTTPDoer()),
+	}
+	return deepseek.NewClientWithOptions("test-key", append(defaults, opts...)...)
+}
+
+// HTTPDoer returns an HTTPDoer that sends every request to the fake server,
+// whatever host the request was built for.
+func (s *Server) HTTPDoer() deepseek.HTTPDoer {
+	return &redirectDoer{target: s.Server.Listener.Addr().String(), client: s.Server.Client()}
+}
+
+// Enqueue scripts the next responses of a route. Scripted responses are served
+// in order; once the queue is empty the route falls back to its default response.
+func (s *Server) Enqueue(route Route, responses ...Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queues[route] = append(s.queues[route], responses...)
+}
+
+// SetDefault sets the response served by a route when its queue is empty.
+func (s *Server) SetDefault(route Route, response Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.defaults[route] = response
+}
+
+// SetBalance sets the payload served by the balance endpoint.
+func (s *Server) SetBalance(balance deepseek.BalanceResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.balance = balance
+}
+
+// SetModels sets the payload served by the models endpoint.
+func (s *Server) SetModels(models deepseek.APIModels) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.models = models
+}
+
+// Requests returns a copy of all requests received so far.
+func (s *Server) Requests() []RecordedRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]RecordedRequest(nil), s.requests...)
+}
+
+// LastRequest returns the most recent request, or false if none was received.
+func (s *Server) LastRequest() (RecordedRequest, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.requests) == 0 {
+		return RecordedRequest{}, false
+	}
+	return s.requests[len(s.requests)-1], true
+}
+
+// Reset clears recorded requests and scripted responses.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = nil
+	s.queues = make(map[Route][]Response)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	route := matchRoute(r.URL.Path)
+
+	s.mu.Lock()
+	s.requests = append(s.requests, RecordedRequest{
+		Route:  route,
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Query:  r.URL.Query(),
+		Header: r.Header.Clone(),
+		Body:   body,
+	})
+	response := s.next(route)
+	balance, models := s.balance, s.models
+	s.mu.Unlock()
+
+	ctx := r.Context()
+	if !sleep(ctx, response.Latency) {
+		return
+	}
+	for key, value := range response.Header {
+		w.Header().Set(key, value)
+	}
+	if response.Error != nil || response.Status >= http.StatusBadRequest {
+		response.writeError(w)
+		return
+	}
+
+	switch route {
+	case RouteChat:
+		if !isPost(w, r) {
+			return
+		}
+		if isStream(body) {
+			response.writeChatStream(ctx, w)
+		} else {
+			writeJSON(w, response.status(), response.bodyOr(response.chatCompletion()))
+		}
+	case RouteFIM:
+		if !isPost(w, r) {
+			return
+		}
+		if isStream(body) {
+			response.writeFIMStream(ctx, w)
+		} else {
+			writeJSON(w, response.status(), response.bodyOr(response.fimCompletion()))
+		}
+	case RouteBalance:
+		writeJSON(w, response.status(), response.bodyOr(balance))
+	case RouteModels:
+		writeJSON(w, response.status(), response.bodyOr(models))
+	default:
+		writeJSON(w, http.StatusNotFound, errorEnvelope{ErrorBody{Message: "not found: " + r.URL.Path, Type: "invalid_request_error"}})
+	}
+}
+
+// next pops the next scripted response of a route. The caller must hold s.mu.
+func (s *Server) next(route Route) Response {
+	queued := s.queues[route]
+	if len(queued) == 0 {
+		return s.defaults[route]
+	}
+	s.queues[route] = queued[1:]
+	return queued[0]
+}
+
+// matchRoute maps a request path to a route, ignoring any prefix such as "/v1" and duplicate slashes.
+func matchRoute(p string) Route {
+	p = path.Clean("/" + p)
+	for _, route := range []Route{RouteFIM, RouteChat, RouteBalance, RouteModels} {
+		if strings.HasSuffix(p, string(route)) {
+			return route
+		}
+	}
+	return ""
+}
+
+func isPost(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, errorEnvelope{ErrorBody{Message: "method not allowed", Type: "invalid_request_error"}})
+		return false
+	}
+	return true
+}
+
+func isStream(body []byte) bool {
+	var req struct {
+		Stream bool `json:"stream"`
+	}
+	_ = json.Unmarshal(body, &req)
+	return req.Stream
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if raw, ok := v.(json.RawMessage); ok {
+		_, _ = w.Write(raw)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// redirectDoer sends every request to the fake server, keeping path and query.
+type redirectDoer struct {
+	target string
+	client *http.Client
+}
+
+func (d *redirectDoer) Do(req *http.Request) (*http.Response, error) {
+	out := req.Clone(req.Context())
+	out.URL.Scheme = "http"
+	out.URL.Host = d.target
+	out.Host = d.target
+	return d.client.Do(out)
+}
@@ -0,0 +1,210 @@
+package deepseektest_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	deepseek "github.com/cohesion-org/deepseek-go"
+	"github.com/cohesion-org/deepseek-go/deepseektest"
+	"github.com/cohesion-org/deepseek-go/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newChatRequest() *deepseek.ChatCompletionRequest {
+	return &deepseek.ChatCompletionRequest{
+		Model: deepseek.DeepSeekChat,
+		Messages: []deepseek.ChatCompletionMessage{
+			{Role: deepseek.ChatMessageRoleUser, Content: "Say hello!"},
+		},
+	}
+}
+
+func TestServer_ChatCompletion(t *testing.T) {
+	srv := deepseektest.NewServer()
+	defer srv.Close()
+	srv.Enqueue(deepseektest.RouteChat, deepseektest.Response{
+		Content:          "Hi there!",
+		ReasoningContent: "The user greeted me.",
+	})
+
+	client, err := srv.Client()
+	require.NoError(t, err)
+
+	resp, err := client.CreateChatCompletion(context.Background(), newChatRequest())
+	require.NoError(t, err)
+	assert.Equal(t, "Hi there!", resp.Choices[0].Message.Content)
+	assert.Equal(t, "The user greeted me.", resp.Choices[0].Message.ReasoningContent)
+	assert.Equal(t, deepseek.FinishReasonStop, resp.Choices[0].FinishReason)
+	assert.NotZero(t, resp.Usage.TotalTokens)
+
+	// The queue is empty now, so the default response is served.
+	resp, err = client.CreateChatCompletion(context.Background(), newChatRequest())
+	require.NoError(t, err)
+	assert.NotEmpty(t, resp.Choices[0].Message.Content)
+
+	requests := srv.Requests()
+	require.Len(t, requests, 2)
+	assert.Equal(t, deepseektest.RouteChat, requests[0].Route)
+	assert.Equal(t, "Bearer test-key", requests[0].Header.Get("Authorization"))
+	chatReq, err := requests[0].ChatRequest()
+	require.NoError(t, err)
+	assert.Equal(t, "Say hello!", chatReq.Messages[0].Content)
+}
+
+func TestServer_ChatCompletionStream(t *testing.T) {
+	srv := deepseektest.NewServer()
+	defer srv.Close()
+	srv.Enqueue(deepseektest.RouteChat, deepseektest.Response{
+		Content:          "Hello streaming world",
+		ReasoningContent: "Thinking hard",
+		ToolCalls: []deepseek.ToolCall{{
+			Function: deepseek.ToolCallFunction{Name: utils.StringPtr("get_weather"), Arguments: `{"city":"Paris"}`},
+		}},
+		KeepAlive: true,
+	})
+
+	client, err := srv.Client()
+	require.NoError(t, err)
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), newChatRequest())
+	require.NoError(t, err)
+	defer stream.Close()
+
+	var content, reasoning, arguments, finishReason string
+	var usage *deepseek.Usage
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		for _, choice := range chunk.Choices {
+			if choice.Delta.Content != nil {
+				content += *choice.Delta.Content
+			}
+			if choice.Delta.ReasoningContent != nil {
+				reasoning += *choice.Delta.ReasoningContent
+			}
+			for _, call := range choice.Delta.ToolCalls {
+				arguments += call.Function.Arguments
+			}
+			if choice.FinishReason != nil {
+				finishReason = string(*choice.FinishReason)
+			}
+		}
+		if chunk.Usage != nil {
+			usage = chunk.Usage
+		}
+	}
+
+	assert.Equal(t, "Hello streaming world", content)
+	assert.Equal(t, "Thinking hard", reasoning)
+	assert.Equal(t, `{"city":"Paris"}`, arguments)
+	assert.Equal(t, "tool_calls", finishReason)
+	require.NotNil(t, usage)
+	assert.NotZero(t, usage.TotalTokens)
+}
+
+func TestServer_InjectedError(t *testing.T) {
+	srv := deepseektest.NewServer()
+	defer srv.Close()
+	srv.Enqueue(deepseektest.RouteChat, deepseektest.Response{
+		Status: http.StatusPaymentRequired,
+		Error:  &deepseektest.ErrorBody{Message: "Insufficient Balance", Type: "unknown_error"},
+	})
+
+	client, err := srv.Client()
+	require.NoError(t, err)
+
+	_, err = client.CreateChatCompletion(context.Background(), newChatRequest())
+	require.Error(t, err)
+	var apiErr *deepseek.APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusPaymentRequired, apiErr.StatusCode)
+	assert.Contains(t, apiErr.ResponseBody, "Insufficient Balance")
+}
+
+func TestServer_Latency(t *testing.T) {
+	srv := deepseektest.NewServer()
+	defer srv.Close()
+	srv.Enqueue(deepseektest.RouteChat, deepseektest.Response{Latency: time.Second})
+
+	client, err := srv.Client()
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = client.CreateChatCompletion(ctx, newChatRequest())
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestServer_FIMCompletion(t *testing.T) {
+	srv := deepseektest.NewServer()
+	defer srv.Close()
+	srv.Enqueue(deepseektest.RouteFIM,
+		deepseektest.Response{Content: "return a + b"},
+		deepseektest.Response{Content: "return a - b"},
+	)
+
+	client, err := srv.Client()
+	require.NoError(t, err)
+
+	resp, err := client.CreateFIMCompletion(context.Background(), &deepseek.FIMCompletionRequest{
+		Model:  deepseek.DeepSeekChat,
+		Prompt: "def add(a, b):",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "return a + b", resp.Choices[0].Text)
+
+	stream, err := client.CreateFIMStreamCompletion(context.Background(), &deepseek.FIMStreamCompletionRequest{
+		Model:  deepseek.DeepSeekChat,
+		Prompt: "def sub(a, b):",
+	})
+	require.NoError(t, err)
+	defer stream.Close()
+
+	var text string
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		text += chunk.Choices[0].Text
+	}
+	assert.Equal(t, "return a - b", text)
+
+	last, ok := srv.LastRequest()
+	require.True(t, ok)
+	assert.Equal(t, deepseektest.RouteFIM, last.Route)
+	fimReq, err := last.FIMRequest()
+	require.NoError(t, err)
+	assert.True(t, fimReq.Stream)
+}
+
+func TestServer_BalanceAndModels(t *testing.T) {
+	srv := deepseektest.NewServer()
+	defer srv.Close()
+	srv.SetModels(deepseek.APIModels{Object: "list", Data: []deepseek.Model{{ID: "custom-model"}}})
+
+	client, err := srv.Client()
+	require.NoError(t, err)
+
+	balance, err := deepseek.GetBalance(client, context.Background())
+	require.NoError(t, err)
+	assert.True(t, balance.IsAvailable)
+	assert.NotEmpty(t, balance.BalanceInfos)
+
+	models, err := deepseek.ListAllModels(client, context.Background())
+	require.NoError(t, err)
+	require.Len(t, models.Data, 1)
+	assert.Equal(t, "custom-model", models.Data[0].ID)
+
+	srv.Reset()
+	assert.Empty(t, srv.Requests())
+}
@@ -2,66 +2,145 @@ package deepseek
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
+)
+
+// Errors an *APIError matches with errors.Is, depending on its status code and message.
+var (
+	ErrAuthentication        = errors.New("authentication failed")   // 401: the API key is invalid.
+	ErrInsufficientBalance   = errors.New("insufficient balance")    // 402: the account ran out of balance.
+	ErrRateLimited           = errors.New("rate limited")            // 429: see APIError.RetryAfter.
+	ErrInvalidRequest        = errors.New("invalid request")         // 400 and 422: the request is malformed or has invalid parameters.
+	ErrContextLengthExceeded = errors.New("context length exceeded") // The messages exceed the model's context window. Also an ErrInvalidRequest.
+	ErrModelNotFound         = errors.New("model not found")         // The requested model does not exist.
+	ErrServerOverloaded      = errors.New("server overloaded")       // 503: the server is under high load.
+	ErrServerError           = errors.New("server error")            // Any 5xx status, including ErrServerOverloaded.
 )
 
 // APIError represents an error returned by the API.
+//
+// It matches the sentinel errors above with errors.Is, for example:
+//
+//	if errors.Is(err, deepseek.ErrRateLimited) {
+//		var apiErr *deepseek.APIError
+//		errors.As(err, &apiErr)
+//		time.Sleep(apiErr.RetryAfter)
+//	}
 type APIError struct {
-	StatusCode    int    // HTTP status code
-	APICode       int    // Business error code from API response
-	Message       string // Human-readable error message
-	OriginalError error  // Wrapped error for debugging
-	ResponseBody  string // Raw JSON response body
+	StatusCode    int           // HTTP status code
+	APICode       int           // Business error code from API response, if it is numeric
+	Code          string        // Error code from API response as sent, e.g. "400" or "context_length_exceeded"
+	Type          string        // Error type from API response, e.g. "invalid_request_error"
+	Param         string        // Request parameter the error relates to, if any
+	Message       string        // Human-readable error message
+	RequestID     string        // ID of the failed request from the response headers, see RequestIDHeaders
+	RetryAfter    time.Duration // Delay requested by the Retry-After header, 0 if there was none
+	OriginalError error         // Wrapped error for debugging
+	ResponseBody  string        // Raw JSON response body
 }
 
+// RequestIDHeaders are the response headers searched, in order, for APIError.RequestID.
+var RequestIDHeaders = []string{"X-Request-Id", "Request-Id", "X-Ds-Trace-Id", "Apim-Request-Id", "X-Ms-Request-Id"}
+
 // Error returns a string representation of the error.
-func (e APIError) Error() string {
-	if e.APICode != 0 {
-		return fmt.Sprintf("HTTP %d (Code %d): %s", e.StatusCode, e.APICode, e.Message)
+func (e *APIError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "HTTP %d", e.StatusCode)
+	switch {
+	case e.APICode != 0:
+		fmt.Fprintf(&b, " (Code %d)", e.APICode)
+	case e.Code != "":
+		fmt.Fprintf(&b, " (Code %s)", e.Code)
+	}
+	fmt.Fprintf(&b, ": %s", e.Message)
+	if e.Param != "" {
+		fmt.Fprintf(&b, " (param: %s)", e.Param)
 	}
-	return fmt.Sprintf("HTTP %d: %s \n%v", e.StatusCode, e.Message, e.ResponseBody)
+	if e.RequestID != "" {
+		fmt.Fprintf(&b, " [request ID: %s]", e.RequestID)
+	}
+	if e.OriginalError != nil && e.ResponseBody != "" {
+		fmt.Fprintf(&b, "\n%s", e.ResponseBody) // The body could not be decoded; show it as is.
+	}
+	return b.String()
 }
 
-// HandleAPIError handles an error response from the API.
-func HandleAPIError(resp *http.Response) error {
-	defer func() { _ = resp.Body.Close() }()
+// Unwrap returns the underlying error, if any.
+func (e *APIError) Unwrap() error {
+	return e.OriginalError
+}
 
-	body, _ := io.ReadAll(resp.Body)
-	responseBody := string(body)
+// Is reports whether the error belongs to the category of target, one of the sentinel errors
+// such as ErrRateLimited.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrAuthentication:
+		return e.StatusCode == http.StatusUnauthorized || e.mentions("authentication_error", "invalid_api_key")
+	case ErrInsufficientBalance:
+		return e.StatusCode == http.StatusPaymentRequired || e.mentions("insufficient_quota", "insufficient balance")
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests || e.mentions("rate_limit")
+	case ErrInvalidRequest:
+		return e.StatusCode == http.StatusBadRequest || e.StatusCode == http.StatusUnprocessableEntity ||
+			e.Type == "invalid_request_error" || e.Is(ErrContextLengthExceeded)
+	case ErrContextLengthExceeded:
+		return e.mentions("context length", "context_length", "context window", "maximum context")
+	case ErrModelNotFound:
+		return e.mentions("model not exist", "model_not_found", "model not found", "no such model") ||
+			(e.StatusCode == http.StatusNotFound && e.mentions("model"))
+	case ErrServerOverloaded:
+		return e.StatusCode == http.StatusServiceUnavailable || e.mentions("overloaded")
+	case ErrServerError:
+		return e.StatusCode >= http.StatusInternalServerError || e.Is(ErrServerOverloaded)
+	}
+	return false
+}
 
-	// Check if the response is HTML
-	if strings.HasPrefix(responseBody, "<html>") {
-		return &APIError{
-			StatusCode: resp.StatusCode,
-			Message:    "Unexpected HTML response (model may not exist). This is likely an issue with the how some external servers return html responses for error.",
+// mentions reports whether the message, type or code of the error contains one of the phrases, ignoring case.
+func (e *APIError) mentions(phrases ...string) bool {
+	text := strings.ToLower(e.Message + "\n" + e.Type + "\n" + e.Code)
+	for _, phrase := range phrases {
+		if strings.Contains(text, phrase) {
+			return true
 		}
 	}
+	return false
+}
 
-	// why are we even defining a new struct here? // We can just use the APIError struct above. // Because we want to parse the JSON response and extract the error message. // We can use the APIError struct above to do that.
-
-	var apiResponse struct {
-		Code    int    `json:"code"`
-		Message string `json:"message"`
-	}
+// HandleAPIError handles an error response from the API. Both the {"error": {"message": ..., "type": ...,
+// "param": ..., "code": ...}} envelope and the flat {"code": ..., "message": ...} form are decoded.
+func HandleAPIError(resp *http.Response) error {
+	defer func() { _ = resp.Body.Close() }()
 
-	err := json.Unmarshal(body, &apiResponse)
+	body, _ := io.ReadAll(resp.Body)
+	responseBody := string(body)
 
 	baseError := &APIError{
 		StatusCode:   resp.StatusCode,
-		APICode:      apiResponse.Code,
-		Message:      apiResponse.Message,
+		RequestID:    requestID(resp.Header),
 		ResponseBody: responseBody,
 	}
+	baseError.RetryAfter, _ = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+
+	// Check if the response is HTML
+	if trimmed := strings.ToLower(strings.TrimSpace(responseBody)); strings.HasPrefix(trimmed, "<html") || strings.HasPrefix(trimmed, "<!doctype html") {
+		baseError.Message = "Unexpected HTML response (model may not exist). This is likely an issue with the how some external servers return html responses for error."
+		baseError.ResponseBody = ""
+		return baseError
+	}
 
-	if err == nil && apiResponse.Code != 0 {
+	err := decodeErrorBody(body, baseError)
+	if err == nil && (baseError.Message != "" || baseError.Code != "") {
 		return baseError
 	}
 
 	// Handle cases where the error response couldn't be parsed
-	baseError.ResponseBody = responseBody
 	switch resp.StatusCode {
 	case http.StatusBadRequest:
 		baseError.Message = "Bad request"
@@ -73,12 +152,105 @@ func HandleAPIError(resp *http.Response) error {
 		baseError.Message = "Rate limit exceeded"
 	case http.StatusNotFound:
 		baseError.Message = "Requested resource not found"
+	case http.StatusUnprocessableEntity:
+		baseError.Message = "Invalid parameters"
 	case http.StatusInternalServerError:
 		baseError.Message = "Internal server error"
+	case http.StatusServiceUnavailable:
+		baseError.Message = "Server overloaded"
 	default:
 		baseError.Message = fmt.Sprintf("Unexpected API response (HTTP %d)", resp.StatusCode)
 	}
 
+	if err == nil {
+		err = fmt.Errorf("no error message")
+	}
 	baseError.OriginalError = fmt.Errorf("failed to decode: %w (body: %s)", err, responseBody)
 	return baseError
 }
+
+// requestID returns the first of RequestIDHeaders present in header.
+func requestID(header http.Header) string {
+	for _, name := range RequestIDHeaders {
+		if id := header.Get(name); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// errorFields are the fields of an error object, in the envelope or at the top level.
+type errorFields struct {
+	Message string          `json:"message"`
+	Type    string          `json:"type"`
+	Param   json.RawMessage `json:"param"`
+	Code    json.RawMessage `json:"code"` // A number for DeepSeek, usually a string for other providers.
+}
+
+// decodeErrorBody fills the fields of apiErr from an error body, which may use the {"error": {...}}
+// envelope or the flat {"code": ..., "message": ...} form.
+func decodeErrorBody(body []byte, apiErr *APIError) error {
+	var payload struct {
+		errorFields
+		Error json.RawMessage `json:"error"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return err
+	}
+	fields := payload.errorFields
+	if len(payload.Error) > 0 && string(payload.Error) != "null" {
+		var envelope errorFields
+		if err := json.Unmarshal(payload.Error, &envelope); err != nil {
+			// Some providers send {"error": "message"}.
+			if err := json.Unmarshal(payload.Error, &envelope.Message); err != nil {
+				return err
+			}
+		}
+		fields = envelope
+	}
+
+	apiErr.Message = fields.Message
+	apiErr.Type = fields.Type
+	apiErr.Param = rawString(fields.Param)
+	apiErr.Code = rawString(fields.Code)
+	apiErr.APICode, _ = strconv.Atoi(apiErr.Code)
+	return nil
+}
+
+// rawString returns a JSON string or number as a string, and "" for null or missing values.
+func rawString(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	var n json.Number
+	if err := json.Unmarshal(raw, &n); err == nil {
+		return n.String()
+	}
+	return ""
+}
+
+// isStreamErrorPayload reports whether the data of a stream event is an {"error": {...}} object
+// rather than a chunk.
+func isStreamErrorPayload(data string) bool {
+	if !strings.Contains(data, `"error"`) {
+		return false
+	}
+	var payload struct {
+		Error json.RawMessage `json:"error"`
+	}
+	return json.Unmarshal([]byte(data), &payload) == nil && len(payload.Error) > 0 && string(payload.Error) != "null"
+}
+
+// streamAPIError builds an APIError from an error sent inside an event stream, which may use
+// the {"error": {...}} envelope or the flat {"code": ..., "message": ...} form.
+func streamAPIError(statusCode int, data string) *APIError {
+	apiErr := &APIError{StatusCode: statusCode, ResponseBody: data}
+	if err := decodeErrorBody([]byte(data), apiErr); err != nil {
+		apiErr.OriginalError = fmt.Errorf("failed to decode stream error: %w", err)
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = data
+	}
+	return apiErr
+}
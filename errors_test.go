@@ -0,0 +1,212 @@
+package deepseek_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/cohesion-org/deepseek-go"
+	"github.com/cohesion-org/deepseek-go/deepseektest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newErrorResponse(status int, body string, header http.Header) *http.Response {
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		StatusCode: status,
+		Header:     header,
+		Body:       io.NopCloser(bytes.NewReader([]byte(body))),
+	}
+}
+
+func TestAPIError_Is(t *testing.T) {
+	sentinels := []error{
+		deepseek.ErrAuthentication,
+		deepseek.ErrInsufficientBalance,
+		deepseek.ErrRateLimited,
+		deepseek.ErrInvalidRequest,
+		deepseek.ErrContextLengthExceeded,
+		deepseek.ErrModelNotFound,
+		deepseek.ErrServerOverloaded,
+		deepseek.ErrServerError,
+	}
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   []error
+	}{
+		{"unauthorized", http.StatusUnauthorized, `{"code": 401, "message": "Authentication Fails"}`, []error{deepseek.ErrAuthentication}},
+		{"balance", http.StatusPaymentRequired, `{"code": 402, "message": "Insufficient Balance"}`, []error{deepseek.ErrInsufficientBalance}},
+		{"rate limit", http.StatusTooManyRequests, ``, []error{deepseek.ErrRateLimited}},
+		{"bad request", http.StatusBadRequest, `{"code": 400, "message": "Invalid format"}`, []error{deepseek.ErrInvalidRequest}},
+		{"invalid parameters", http.StatusUnprocessableEntity, ``, []error{deepseek.ErrInvalidRequest}},
+		{
+			"context length", http.StatusBadRequest,
+			`{"code": 400, "message": "This model's maximum context length is 65536 tokens."}`,
+			[]error{deepseek.ErrInvalidRequest, deepseek.ErrContextLengthExceeded},
+		},
+		{
+			"model not exist", http.StatusBadRequest, `{"code": 400, "message": "Model Not Exist"}`,
+			[]error{deepseek.ErrInvalidRequest, deepseek.ErrModelNotFound},
+		},
+		{"overloaded", http.StatusServiceUnavailable, ``, []error{deepseek.ErrServerOverloaded, deepseek.ErrServerError}},
+		{"server error", http.StatusInternalServerError, ``, []error{deepseek.ErrServerError}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := deepseek.HandleAPIError(newErrorResponse(tt.status, tt.body, nil))
+			for _, sentinel := range sentinels {
+				want := false
+				for _, w := range tt.want {
+					want = want || w == sentinel
+				}
+				assert.Equal(t, want, errors.Is(err, sentinel), "errors.Is(err, %v)", sentinel)
+			}
+		})
+	}
+}
+
+func TestAPIError_RetryAfter(t *testing.T) {
+	err := deepseek.HandleAPIError(newErrorResponse(http.StatusTooManyRequests, ``, http.Header{"Retry-After": {"7"}}))
+	var apiErr *deepseek.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, 7*time.Second, apiErr.RetryAfter)
+
+	srv, client := newTestServer(t)
+	srv.Enqueue(deepseektest.RouteChat, deepseektest.Response{
+		Status: http.StatusTooManyRequests,
+		Header: map[string]string{"Retry-After": "3"},
+		Error:  &deepseektest.ErrorBody{Message: "Rate limit reached"},
+	})
+	_, err = client.CreateChatCompletion(context.Background(), testChatRequest())
+	require.ErrorIs(t, err, deepseek.ErrRateLimited)
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, 3*time.Second, apiErr.RetryAfter)
+}
+
+func TestAPIError_Unwrap(t *testing.T) {
+	err := deepseek.HandleAPIError(newErrorResponse(http.StatusBadGateway, `not json`, nil))
+	var syntaxErr *json.SyntaxError
+	assert.ErrorAs(t, err, &syntaxErr, "the decoding error is wrapped")
+
+	var target error = &deepseek.APIError{StatusCode: http.StatusUnauthorized}
+	assert.ErrorIs(t, target, deepseek.ErrAuthentication)
+	assert.NotErrorIs(t, target, deepseek.ErrRateLimited)
+}
+
+func TestDecodeError(t *testing.T) {
+	resp := newErrorResponse(http.StatusOK, `<!DOCTYPE html><html></html>`, nil)
+	_, err := deepseek.HandleChatCompletionResponse(resp)
+	var decodeErr *deepseek.DecodeError
+	require.ErrorAs(t, err, &decodeErr)
+	assert.Equal(t, http.StatusOK, decodeErr.StatusCode)
+	assert.Contains(t, decodeErr.Message, "HTML")
+	assert.Nil(t, decodeErr.Err)
+
+	resp = newErrorResponse(http.StatusOK, `{"choices": `, nil)
+	_, err = deepseek.HandleFIMCompletionRequest(resp)
+	require.ErrorAs(t, err, &decodeErr)
+	assert.Equal(t, `{"choices": `, decodeErr.ResponseBody)
+	var syntaxErr *json.SyntaxError
+	assert.ErrorAs(t, err, &syntaxErr)
+}
+
+func TestHandleAPIError_Envelope(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want deepseek.APIError
+	}{
+		{
+			name: "nested with string code",
+			body: `{"error": {"message": "This model's maximum context length is 65536 tokens.", "type": "invalid_request_error", "param": "messages", "code": "context_length_exceeded"}}`,
+			want: deepseek.APIError{
+				Message: "This model's maximum context length is 65536 tokens.",
+				Type:    "invalid_request_error",
+				Param:   "messages",
+				Code:    "context_length_exceeded",
+			},
+		},
+		{
+			name: "nested with numeric code",
+			body: `{"error": {"message": "Model Not Exist", "type": "invalid_request_error", "param": null, "code": 400}}`,
+			want: deepseek.APIError{Message: "Model Not Exist", Type: "invalid_request_error", Code: "400", APICode: 400},
+		},
+		{
+			name: "flat",
+			body: `{"code": 400, "message": "Invalid format"}`,
+			want: deepseek.APIError{Message: "Invalid format", Code: "400", APICode: 400},
+		},
+		{
+			name: "flat without code",
+			body: `{"message": "Invalid format"}`,
+			want: deepseek.APIError{Message: "Invalid format"},
+		},
+		{
+			name: "string error",
+			body: `{"error": "Invalid format"}`,
+			want: deepseek.APIError{Message: "Invalid format"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := newErrorResponse(http.StatusBadRequest, tt.body, http.Header{"X-Request-Id": {"req-123"}})
+			err := deepseek.HandleAPIError(resp)
+			var apiErr *deepseek.APIError
+			require.ErrorAs(t, err, &apiErr)
+			assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+			assert.Equal(t, tt.want.Message, apiErr.Message)
+			assert.Equal(t, tt.want.Type, apiErr.Type)
+			assert.Equal(t, tt.want.Param, apiErr.Param)
+			assert.Equal(t, tt.want.Code, apiErr.Code)
+			assert.Equal(t, tt.want.APICode, apiErr.APICode)
+			assert.Equal(t, "req-123", apiErr.RequestID)
+			assert.Equal(t, tt.body, apiErr.ResponseBody)
+			assert.NoError(t, apiErr.OriginalError)
+			assert.Contains(t, err.Error(), "req-123")
+		})
+	}
+}
+
+func TestHandleAPIError_Fallbacks(t *testing.T) {
+	err := deepseek.HandleAPIError(newErrorResponse(http.StatusUnauthorized, `{}`, nil))
+	var apiErr *deepseek.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "Invalid authentication credentials", apiErr.Message)
+	assert.Error(t, apiErr.OriginalError)
+
+	err = deepseek.HandleAPIError(newErrorResponse(http.StatusNotFound, "<!DOCTYPE html>\n<html></html>", nil))
+	require.ErrorAs(t, err, &apiErr)
+	assert.Contains(t, apiErr.Message, "HTML")
+
+	header := http.Header{"Apim-Request-Id": {"azure-1"}}
+	err = deepseek.HandleAPIError(newErrorResponse(http.StatusTooManyRequests, `{"error": {"message": "slow down", "code": "rate_limit_exceeded"}}`, header))
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "azure-1", apiErr.RequestID)
+	assert.ErrorIs(t, err, deepseek.ErrRateLimited)
+}
+
+func TestAPIError_IsByCode(t *testing.T) {
+	// In-band stream errors arrive with status 200, so they are classified by their type and code.
+	tests := map[string]error{
+		`{"error": {"message": "too long", "code": "context_length_exceeded"}}`:     deepseek.ErrContextLengthExceeded,
+		`{"error": {"message": "unknown", "code": "model_not_found"}}`:              deepseek.ErrModelNotFound,
+		`{"error": {"message": "no", "type": "authentication_error"}}`:              deepseek.ErrAuthentication,
+		`{"error": {"message": "Server is overloaded", "type": "server_error"}}`:    deepseek.ErrServerOverloaded,
+		`{"error": {"message": "wait", "code": "rate_limit_exceeded"}}`:             deepseek.ErrRateLimited,
+		`{"error": {"message": "bad", "type": "invalid_request_error", "code": 7}}`: deepseek.ErrInvalidRequest,
+	}
+	for body, sentinel := range tests {
+		err := deepseek.HandleAPIError(newErrorResponse(http.StatusOK, body, nil))
+		assert.ErrorIs(t, err, sentinel, body)
+	}
+}
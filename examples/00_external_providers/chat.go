@@ -45,3 +45,30 @@ func ExternalProviders() {
 	// Print the response
 	fmt.Println("Response:", response.Choices[0].Message.Content)
 }
+
+// ProviderProfiles demonstrates how to switch between providers without changing the requests.
+// The provider maps DeepSeek model names to its own, e.g. deepseek.DeepSeekReasoner to
+// deepseek.AzureDeepSeekR1, and normalizes responses, e.g. OpenRouter's "reasoning" field.
+func ProviderProfiles() {
+	provider := deepseek.AzureProvider("", "")
+	// provider := deepseek.OpenRouterProvider("https://example.com", "My App")
+	// provider := deepseek.OllamaProvider("")
+
+	client, err := deepseek.NewClientWithOptions(os.Getenv("PROVIDER_API_KEY"), deepseek.WithProvider(provider))
+	if err != nil {
+		log.Fatalf("error creating client: %v", err)
+	}
+
+	request := &deepseek.ChatCompletionRequest{
+		Model: deepseek.DeepSeekReasoner,
+		Messages: []deepseek.ChatCompletionMessage{
+			{Role: deepseek.ChatMessageRoleUser, Content: "Which is the tallest mountain in the world?"},
+		},
+	}
+	response, err := client.CreateChatCompletion(context.Background(), request)
+	if err != nil {
+		log.Fatalf("error: %v", err)
+	}
+	fmt.Println("Reasoning:", response.Choices[0].Message.ReasoningContent)
+	fmt.Println("Response:", response.Choices[0].Message.Content)
+}
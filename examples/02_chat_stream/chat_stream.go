@@ -41,8 +41,10 @@ func Streaming() {
 			break
 		}
 		for _, choice := range response.Choices {
-			fullMessage += choice.Delta.Content // Accumulate chunk content
-			log.Println(choice.Delta.Content)
+			if choice.Delta.Content != nil {
+				fullMessage += *choice.Delta.Content // Accumulate chunk content
+				log.Println(*choice.Delta.Content)
+			}
 		}
 	}
 	log.Println("The full message is: ", fullMessage)
@@ -79,13 +81,13 @@ func StreamingWithReasoningContent() {
 			break
 		}
 		for _, choice := range response.Choices {
-			fullMessage += choice.Delta.Content            // Accumulate chunk content
-			fullReasoning += choice.Delta.ReasoningContent // Accumulate chunk reasoning content
-			if choice.Delta.ReasoningContent != "" {
-				log.Println("Reasoning: ", choice.Delta.ReasoningContent)
+			if choice.Delta.ReasoningContent != nil && *choice.Delta.ReasoningContent != "" {
+				fullReasoning += *choice.Delta.ReasoningContent // Accumulate chunk reasoning content
+				log.Println("Reasoning: ", *choice.Delta.ReasoningContent)
 			}
-			if choice.Delta.Content != "" {
-				log.Println("Content:", choice.Delta.Content)
+			if choice.Delta.Content != nil && *choice.Delta.Content != "" {
+				fullMessage += *choice.Delta.Content // Accumulate chunk content
+				log.Println("Content:", *choice.Delta.Content)
 			}
 		}
 		if streamUsage := response.Usage; streamUsage != nil && streamUsage.TotalTokens > 0 {
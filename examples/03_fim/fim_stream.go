@@ -23,9 +23,9 @@ func FIMStream() {
 	if err != nil {
 		log.Fatalf("FIMCompletionStream error: %v", err)
 	}
-	defer stream.FIMClose()
+	defer stream.Close()
 	for {
-		response, err := stream.FIMRecv()
+		response, err := stream.Recv()
 		if errors.Is(err, io.EOF) {
 			fmt.Println("\nStream finished")
 			break
@@ -54,3 +54,24 @@ func MultiChat() {
 	log.Printf("The messages after response 1 are: %v", messages)
 
 }
+
+// ConversationChat has the same two rounds as MultiChat, with a Conversation keeping the history.
+func ConversationChat() {
+	client := deepseek.NewClient("DEEPSEEK_API_KEY")
+	ctx := context.Background()
+
+	conversation := deepseek.NewConversation(client, deepseek.DeepSeekChat, "")
+
+	// Round 1
+	if _, err := conversation.Send(ctx, "Who is the president of the United States? One word response only."); err != nil {
+		log.Fatalf("Round 1 failed: %v", err)
+	}
+
+	// Round 2: the question and the answer of round 1 are sent along.
+	response, err := conversation.Send(ctx, "Who was the one in the previous term.")
+	if err != nil {
+		log.Fatalf("Round 2 failed: %v", err)
+	}
+	log.Printf("Answer: %s", response.Choices[0].Message.Content)
+	log.Printf("The messages after response 2 are: %v", conversation.Messages())
+}
@@ -7,6 +7,7 @@ import (
 	"log"
 
 	deepseek "github.com/cohesion-org/deepseek-go"
+	"github.com/cohesion-org/deepseek-go/utils"
 )
 
 // MultiChatStream demonstrates how to use the ChatStream API for multi-turn chat completion.
@@ -54,11 +55,10 @@ func MultiChatStream() {
 
 // Helper function to handle streaming chat completion. Just returns the final message for this example.
 func streamChatCompletion(ctx context.Context, client *deepseek.Client, messages []deepseek.ChatCompletionMessage) (string, error) {
-	request := &deepseek.StreamChatCompletionRequest{
+	request := &deepseek.ChatCompletionRequest{
 		Model:       deepseek.DeepSeekChat,
 		Messages:    messages,
-		Stream:      true,
-		Temperature: 1.5,
+		Temperature: utils.Float32Ptr(1.5),
 	}
 
 	stream, err := client.CreateChatCompletionStream(ctx, request)
@@ -78,7 +78,9 @@ func streamChatCompletion(ctx context.Context, client *deepseek.Client, messages
 			return "", err
 		}
 		for _, choice := range response.Choices {
-			fullMessage += choice.Delta.Content // Accumulate chunk content
+			if choice.Delta.Content != nil {
+				fullMessage += *choice.Delta.Content // Accumulate chunk content
+			}
 		}
 	}
 	return fullMessage, nil
@@ -72,7 +72,7 @@ func FunctionCalling() {
 	answer := deepseek.ChatCompletionMessage{
 		Role:       deepseek.ChatMessageRoleTool,
 		Content:    onGetTime(),
-		ToolCallID: toolCalls[0].ID,
+		ToolCallID: *toolCalls[0].ID,
 	}
 
 	messages := request.Messages
@@ -0,0 +1,49 @@
+package deepseek_examples
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/cohesion-org/deepseek-go"
+)
+
+type timeArgs struct {
+	Timezone string `json:"timezone,omitempty" description:"IANA time zone, e.g. Europe/Paris" default:"UTC"`
+}
+
+// FunctionCallingWithRegistry does the round trip of FunctionCalling with a ToolRegistry: the runner
+// executes the tool calls and sends their results back until the model answers.
+func FunctionCallingWithRegistry() {
+	client := deepseek.NewClient(os.Getenv("DEEPSEEK_API_KEY"))
+
+	registry := deepseek.NewToolRegistry()
+	err := deepseek.RegisterTool(registry, "GetTime", "Get the current date and time in RFC3339 format.",
+		func(ctx context.Context, args timeArgs) (string, error) {
+			location, err := time.LoadLocation(args.Timezone)
+			if err != nil {
+				return "", err // The error is sent to the model, which can retry with another time zone.
+			}
+			return time.Now().In(location).Format(time.RFC3339), nil
+		})
+	if err != nil {
+		log.Fatalf("error: %v", err)
+	}
+
+	runner := deepseek.NewToolRunner(client, registry)
+	runner.MaxIterations = 5
+	result, err := runner.Run(context.Background(), &deepseek.ChatCompletionRequest{
+		Model: deepseek.DeepSeekChat,
+		Messages: []deepseek.ChatCompletionMessage{
+			{Role: deepseek.ChatMessageRoleUser, Content: "What time is it in Tokyo?"},
+		},
+	})
+	if err != nil {
+		log.Fatalf("error: %v", err)
+	}
+
+	fmt.Println("response:", result.Response.Choices[0].Message.Content)
+	fmt.Println("completions:", result.Iterations, "tokens:", result.Usage.TotalTokens)
+}
@@ -0,0 +1,7 @@
+package deepseek
+
+// SetFailoverRandom replaces the random source that orders the endpoints of f, so that tests
+// can fix the order.
+func SetFailoverRandom(f *FailoverClient, random func() float64) {
+	f.random = random
+}
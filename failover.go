@@ -0,0 +1,312 @@
+package deepseek
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+)
+
+// CompletionClient is the set of completion methods shared by Client and FailoverClient,
+// so that code can accept either of them.
+type CompletionClient interface {
+	CreateChatCompletion(ctx context.Context, request *ChatCompletionRequest) (*ChatCompletionResponse, error)
+	CreateChatCompletionStream(ctx context.Context, request *ChatCompletionRequest) (ChatCompletionStream, error)
+	CreateFIMCompletion(ctx context.Context, request *FIMCompletionRequest) (*FIMCompletionResponse, error)
+	CreateFIMStreamCompletion(ctx context.Context, request *FIMStreamCompletionRequest) (FIMChatCompletionStream, error)
+}
+
+var (
+	_ CompletionClient = (*Client)(nil)
+	_ CompletionClient = (*FailoverClient)(nil)
+)
+
+// Default values used by FailoverClient when a field is left at its zero value.
+const (
+	DefaultFailureThreshold = 3
+	DefaultFailoverCooldown = 30 * time.Second
+)
+
+// ErrNoAvailableEndpoint is returned by FailoverClient when the circuits of all endpoints are open.
+var ErrNoAvailableEndpoint = errors.New("no available endpoint")
+
+// CircuitState is the state of an endpoint's circuit breaker.
+type CircuitState string
+
+// States of an endpoint's circuit breaker.
+const (
+	CircuitClosed   CircuitState = "closed"    // The endpoint receives requests.
+	CircuitOpen     CircuitState = "open"      // The endpoint failed repeatedly and is skipped until its cooldown ends.
+	CircuitHalfOpen CircuitState = "half_open" // The cooldown ended; a single trial request decides whether the circuit closes.
+)
+
+// Endpoint is one of the clients a FailoverClient routes requests to.
+type Endpoint struct {
+	Name   string  // Name used in statistics and errors. Defaults to the client's BaseURL.
+	Client *Client // The configured client, e.g. with WithProvider for Azure.
+	Weight int     // Relative share of the requests. Defaults to 1.
+}
+
+// EndpointStats is a snapshot of the statistics of an endpoint.
+type EndpointStats struct {
+	Name                string
+	Weight              int
+	State               CircuitState
+	Requests            int           // Requests sent to the endpoint.
+	Failures            int           // Requests that failed and were passed on to the next endpoint.
+	ConsecutiveFailures int           // Failures since the last successful request.
+	AverageLatency      time.Duration // Average duration of the requests, including failed ones.
+	LastError           error         // Error of the last failure, nil if there was none.
+	LastFailure         time.Time     // Time of the last failure.
+}
+
+// FailoverClient spreads requests over several endpoints according to their weights, and moves on
+// to the next endpoint when one fails with a 5xx status, 402 (insufficient balance), a timeout or
+// a failure to connect. Other errors, such as 400 or 401, or a connection reset after the request
+// was sent, are returned to the caller as they are.
+//
+// An endpoint that fails FailureThreshold times in a row is ejected by a circuit breaker for
+// Cooldown, after which a single trial request decides whether it is used again.
+//
+// Streams fail over only while being created; errors after the first chunk are returned by Recv.
+// Model names are sent to every endpoint unchanged, so endpoints of other providers should be
+// configured with WithProvider to map them. FailoverClient is safe for concurrent use.
+type FailoverClient struct {
+	FailureThreshold int           // Consecutive failures that open an endpoint's circuit. Defaults to 3.
+	Cooldown         time.Duration // How long an open circuit skips its endpoint. Defaults to 30s.
+
+	// ShouldFailover optionally overrides which errors are passed on to the next endpoint
+	// and count as failures of the endpoint.
+	ShouldFailover func(err error) bool
+
+	mu        sync.Mutex
+	endpoints []*endpointState
+	now       func() time.Time
+	random    func() float64 // Source of the weighted order of the endpoints.
+}
+
+type endpointState struct {
+	Endpoint
+	stats        EndpointStats
+	totalLatency time.Duration
+	openedAt     time.Time // Zero while the circuit is closed.
+	probing      bool      // Whether the trial request of a half-open circuit is in flight.
+}
+
+// NewFailoverClient creates a FailoverClient.
+func NewFailoverClient(endpoints ...Endpoint) (*FailoverClient, error) {
+	if len(endpoints) == 0 {
+		return nil, fmt.Errorf("at least one endpoint is required")
+	}
+	f := &FailoverClient{now: time.Now, random: rand.Float64}
+	for i, e := range endpoints {
+		if e.Client == nil {
+			return nil, fmt.Errorf("client of endpoint %d cannot be nil", i)
+		}
+		if e.Weight < 0 {
+			return nil, fmt.Errorf("weight of endpoint %d must not be negative", i)
+		}
+		if e.Weight == 0 {
+			e.Weight = 1
+		}
+		if e.Name == "" {
+			e.Name = e.Client.BaseURL
+		}
+		f.endpoints = append(f.endpoints, &endpointState{Endpoint: e})
+	}
+	return f, nil
+}
+
+// CreateChatCompletion sends a chat completion request to the first endpoint that succeeds.
+func (f *FailoverClient) CreateChatCompletion(
+	ctx context.Context,
+	request *ChatCompletionRequest,
+) (*ChatCompletionResponse, error) {
+	return failover(ctx, f, func(c *Client) (*ChatCompletionResponse, error) {
+		return c.CreateChatCompletion(ctx, request)
+	})
+}
+
+// CreateChatCompletionStream opens a chat completion stream on the first endpoint that succeeds.
+func (f *FailoverClient) CreateChatCompletionStream(
+	ctx context.Context,
+	request *ChatCompletionRequest,
+) (ChatCompletionStream, error) {
+	return failover(ctx, f, func(c *Client) (ChatCompletionStream, error) {
+		return c.CreateChatCompletionStream(ctx, request)
+	})
+}
+
+// CreateFIMCompletion sends a FIM completion request to the first endpoint that succeeds.
+func (f *FailoverClient) CreateFIMCompletion(
+	ctx context.Context,
+	request *FIMCompletionRequest,
+) (*FIMCompletionResponse, error) {
+	return failover(ctx, f, func(c *Client) (*FIMCompletionResponse, error) {
+		return c.CreateFIMCompletion(ctx, request)
+	})
+}
+
+// CreateFIMStreamCompletion opens a FIM completion stream on the first endpoint that succeeds.
+func (f *FailoverClient) CreateFIMStreamCompletion(
+	ctx context.Context,
+	request *FIMStreamCompletionRequest,
+) (FIMChatCompletionStream, error) {
+	return failover(ctx, f, func(c *Client) (FIMChatCompletionStream, error) {
+		return c.CreateFIMStreamCompletion(ctx, request)
+	})
+}
+
+// Stats returns the statistics of the endpoints, in the order they were given to NewFailoverClient.
+func (f *FailoverClient) Stats() []EndpointStats {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := f.now()
+	stats := make([]EndpointStats, len(f.endpoints))
+	for i, e := range f.endpoints {
+		stats[i] = e.stats
+		stats[i].Name = e.Name
+		stats[i].Weight = e.Weight
+		stats[i].State = f.state(e, now)
+		if e.stats.Requests > 0 {
+			stats[i].AverageLatency = e.totalLatency / time.Duration(e.stats.Requests)
+		}
+	}
+	return stats
+}
+
+// failover calls call with the client of each available endpoint until one succeeds
+// or fails with an error that is not passed on.
+func failover[T any](ctx context.Context, f *FailoverClient, call func(c *Client) (T, error)) (T, error) {
+	var zero T
+	var errs []error
+	for _, e := range f.order() {
+		if !f.acquire(e) {
+			continue
+		}
+		start := f.now()
+		result, err := call(e.Client)
+		if err != nil && ctx.Err() != nil {
+			// The caller gave up, which says nothing about the endpoint.
+			f.release(e, f.now().Sub(start))
+			return zero, err
+		}
+		failed := err != nil && f.shouldFailover(ctx, err)
+		f.record(e, f.now().Sub(start), err, failed)
+		if !failed {
+			return result, err
+		}
+		errs = append(errs, fmt.Errorf("endpoint %s: %w", e.Name, err))
+	}
+	if len(errs) == 0 {
+		return zero, ErrNoAvailableEndpoint
+	}
+	return zero, fmt.Errorf("all endpoints failed: %w", errors.Join(errs...))
+}
+
+// order returns the endpoints in a random order weighted by their weights.
+func (f *FailoverClient) order() []*endpointState {
+	keys := make(map[*endpointState]float64, len(f.endpoints))
+	ordered := make([]*endpointState, len(f.endpoints))
+	copy(ordered, f.endpoints)
+	for _, e := range ordered {
+		// Exponential keys give a weighted sample without replacement (Efraimidis-Spirakis).
+		keys[e] = -math.Log(1-f.random()) / float64(e.Weight)
+	}
+	sort.SliceStable(ordered, func(i, j int) bool { return keys[ordered[i]] < keys[ordered[j]] })
+	return ordered
+}
+
+// acquire reports whether a request may be sent to e, and reserves the trial request of a half-open circuit.
+func (f *FailoverClient) acquire(e *endpointState) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch f.state(e, f.now()) {
+	case CircuitOpen:
+		return false
+	case CircuitHalfOpen:
+		if e.probing {
+			return false
+		}
+		e.probing = true
+	}
+	return true
+}
+
+// record updates the statistics and the circuit of e after a request.
+func (f *FailoverClient) record(e *endpointState, latency time.Duration, err error, failed bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	e.probing = false
+	e.stats.Requests++
+	e.totalLatency += latency
+	if !failed {
+		e.stats.ConsecutiveFailures = 0
+		e.openedAt = time.Time{}
+		return
+	}
+	now := f.now()
+	e.stats.Failures++
+	e.stats.ConsecutiveFailures++
+	e.stats.LastError = err
+	e.stats.LastFailure = now
+	if !e.openedAt.IsZero() || e.stats.ConsecutiveFailures >= f.failureThreshold() {
+		e.openedAt = now // A failed trial request opens the circuit again.
+	}
+}
+
+// release counts a request to e that was abandoned by its caller, without changing the circuit of e
+// other than freeing the trial request of a half-open circuit.
+func (f *FailoverClient) release(e *endpointState, latency time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	e.probing = false
+	e.stats.Requests++
+	e.totalLatency += latency
+}
+
+// state returns the circuit state of e. The caller must hold f.mu.
+func (f *FailoverClient) state(e *endpointState, now time.Time) CircuitState {
+	switch {
+	case e.openedAt.IsZero():
+		return CircuitClosed
+	case now.Sub(e.openedAt) < f.cooldown():
+		return CircuitOpen
+	default:
+		return CircuitHalfOpen
+	}
+}
+
+func (f *FailoverClient) failureThreshold() int {
+	if f.FailureThreshold > 0 {
+		return f.FailureThreshold
+	}
+	return DefaultFailureThreshold
+}
+
+func (f *FailoverClient) cooldown() time.Duration {
+	if f.Cooldown > 0 {
+		return f.Cooldown
+	}
+	return DefaultFailoverCooldown
+}
+
+// shouldFailover reports whether err is a failure of the endpoint rather than of the request.
+func (f *FailoverClient) shouldFailover(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false // The caller gave up; other endpoints would not fare better.
+	}
+	if f.ShouldFailover != nil {
+		return f.ShouldFailover(err)
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return errors.Is(apiErr, ErrServerError) || errors.Is(apiErr, ErrInsufficientBalance)
+	}
+	// Completions are not idempotent: only fail over on network errors that happen before sending.
+	return errors.Is(err, context.DeadlineExceeded) || isRetryableTransportError(err, false)
+}
@@ -0,0 +1,206 @@
+package deepseek_test
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/cohesion-org/deepseek-go"
+	"github.com/cohesion-org/deepseek-go/deepseektest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newFailoverEndpoint(t *testing.T, name string, weight int) (*deepseektest.Server, deepseek.Endpoint) {
+	t.Helper()
+	srv, client := newTestServer(t)
+	return srv, deepseek.Endpoint{Name: name, Client: client, Weight: weight}
+}
+
+// newOrderedFailoverClient creates a FailoverClient that tries its endpoints of equal weights in the order given.
+func newOrderedFailoverClient(t *testing.T, endpoints ...deepseek.Endpoint) *deepseek.FailoverClient {
+	t.Helper()
+	client, err := deepseek.NewFailoverClient(endpoints...)
+	require.NoError(t, err)
+	// With a constant random source, equal weights give equal keys and the stable sort keeps the order.
+	deepseek.SetFailoverRandom(client, func() float64 { return 0.5 })
+	return client
+}
+
+func TestFailoverClient_FailsOver(t *testing.T) {
+	primarySrv, primary := newFailoverEndpoint(t, "primary", 1)
+	backupSrv, backup := newFailoverEndpoint(t, "backup", 1)
+	primarySrv.Enqueue(deepseektest.RouteChat,
+		deepseektest.Response{Status: http.StatusServiceUnavailable, Error: &deepseektest.ErrorBody{Message: "overloaded"}},
+		deepseektest.Response{Status: http.StatusPaymentRequired, Error: &deepseektest.ErrorBody{Message: "Insufficient Balance"}},
+	)
+	backupSrv.SetDefault(deepseektest.RouteChat, deepseektest.Response{Content: "from backup"})
+
+	client := newOrderedFailoverClient(t, primary, backup)
+
+	resp, err := client.CreateChatCompletion(context.Background(), testChatRequest())
+	require.NoError(t, err)
+	assert.Equal(t, "from backup", resp.Choices[0].Message.Content)
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), testChatRequest())
+	require.NoError(t, err)
+	streamed, err := deepseek.AccumulateStream(stream)
+	require.NoError(t, err)
+	assert.Equal(t, "from backup", streamed.Choices[0].Message.Content)
+
+	stats := client.Stats()
+	require.Len(t, stats, 2)
+	assert.Equal(t, "primary", stats[0].Name)
+	assert.Equal(t, 2, stats[0].Requests)
+	assert.Equal(t, 2, stats[0].Failures)
+	assert.Equal(t, 2, stats[0].ConsecutiveFailures)
+	assert.Equal(t, deepseek.CircuitClosed, stats[0].State)
+	var apiErr *deepseek.APIError
+	require.ErrorAs(t, stats[0].LastError, &apiErr)
+	assert.Equal(t, http.StatusPaymentRequired, apiErr.StatusCode)
+	assert.Equal(t, 2, stats[1].Requests)
+	assert.Zero(t, stats[1].Failures)
+}
+
+func TestFailoverClient_DoesNotFailOverClientErrors(t *testing.T) {
+	primarySrv, primary := newFailoverEndpoint(t, "primary", 1)
+	backupSrv, backup := newFailoverEndpoint(t, "backup", 1)
+	primarySrv.Enqueue(deepseektest.RouteChat,
+		deepseektest.Response{Status: http.StatusBadRequest, Error: &deepseektest.ErrorBody{Message: "invalid"}})
+
+	client := newOrderedFailoverClient(t, primary, backup)
+
+	_, err := client.CreateChatCompletion(context.Background(), testChatRequest())
+	var apiErr *deepseek.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+	assert.Empty(t, backupSrv.Requests())
+	assert.Zero(t, client.Stats()[0].Failures)
+}
+
+func TestFailoverClient_Timeout(t *testing.T) {
+	slowSrv, slow := newFailoverEndpoint(t, "slow", 1)
+	_, fast := newFailoverEndpoint(t, "fast", 1)
+	slow.Client.Timeout = 50 * time.Millisecond
+	slowSrv.Enqueue(deepseektest.RouteChat, deepseektest.Response{Latency: time.Second})
+
+	client := newOrderedFailoverClient(t, slow, fast)
+
+	_, err := client.CreateChatCompletion(context.Background(), testChatRequest())
+	require.NoError(t, err)
+	assert.Equal(t, 1, client.Stats()[0].Failures)
+}
+
+func TestFailoverClient_CircuitBreaker(t *testing.T) {
+	flakySrv, flaky := newFailoverEndpoint(t, "flaky", 1)
+	_, backup := newFailoverEndpoint(t, "backup", 1)
+	flakySrv.SetDefault(deepseektest.RouteChat, deepseektest.Response{Status: http.StatusInternalServerError})
+
+	client := newOrderedFailoverClient(t, flaky, backup)
+	client.FailureThreshold = 2
+	client.Cooldown = 100 * time.Millisecond
+
+	for i := 0; i < 4; i++ {
+		_, err := client.CreateChatCompletion(context.Background(), testChatRequest())
+		require.NoError(t, err)
+	}
+	stats := client.Stats()
+	assert.Equal(t, deepseek.CircuitOpen, stats[0].State)
+	assert.Equal(t, 2, stats[0].Requests, "the endpoint is skipped once its circuit is open")
+	assert.Equal(t, 4, stats[1].Requests)
+
+	time.Sleep(150 * time.Millisecond)
+	assert.Equal(t, deepseek.CircuitHalfOpen, client.Stats()[0].State)
+	flakySrv.SetDefault(deepseektest.RouteChat, deepseektest.Response{Content: "recovered"})
+	resp, err := client.CreateChatCompletion(context.Background(), testChatRequest())
+	require.NoError(t, err)
+	assert.Equal(t, "recovered", resp.Choices[0].Message.Content)
+	assert.Equal(t, deepseek.CircuitClosed, client.Stats()[0].State)
+}
+
+func TestFailoverClient_AllFailed(t *testing.T) {
+	srvA, a := newFailoverEndpoint(t, "a", 1)
+	srvB, b := newFailoverEndpoint(t, "b", 1)
+	srvA.SetDefault(deepseektest.RouteChat, deepseektest.Response{Status: http.StatusBadGateway})
+	srvB.SetDefault(deepseektest.RouteChat, deepseektest.Response{Status: http.StatusBadGateway})
+
+	client, err := deepseek.NewFailoverClient(a, b)
+	require.NoError(t, err)
+	client.FailureThreshold = 1
+
+	_, err = client.CreateChatCompletion(context.Background(), testChatRequest())
+	var apiErr *deepseek.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusBadGateway, apiErr.StatusCode)
+	assert.Contains(t, err.Error(), "endpoint a")
+	assert.Contains(t, err.Error(), "endpoint b")
+
+	_, err = client.CreateChatCompletion(context.Background(), testChatRequest())
+	assert.ErrorIs(t, err, deepseek.ErrNoAvailableEndpoint)
+}
+
+func TestFailoverClient_Weights(t *testing.T) {
+	_, heavy := newFailoverEndpoint(t, "heavy", 9)
+	_, light := newFailoverEndpoint(t, "light", 1)
+	client, err := deepseek.NewFailoverClient(heavy, light)
+	require.NoError(t, err)
+	deepseek.SetFailoverRandom(client, rand.New(rand.NewPCG(1, 2)).Float64)
+
+	for i := 0; i < 100; i++ {
+		_, err := client.CreateFIMCompletion(context.Background(), &deepseek.FIMCompletionRequest{
+			Model:  deepseek.DeepSeekChat,
+			Prompt: "def add(a, b):",
+		})
+		require.NoError(t, err)
+	}
+	stats := client.Stats()
+	assert.Equal(t, 100, stats[0].Requests+stats[1].Requests)
+	assert.Greater(t, stats[0].Requests, 70)
+	assert.Greater(t, stats[1].Requests, 0)
+}
+
+func TestNewFailoverClient_Validation(t *testing.T) {
+	_, err := deepseek.NewFailoverClient()
+	assert.Error(t, err)
+	_, err = deepseek.NewFailoverClient(deepseek.Endpoint{Name: "nil"})
+	assert.Error(t, err)
+	_, err = deepseek.NewFailoverClient(deepseek.Endpoint{Client: deepseek.NewClient("key"), Weight: -1})
+	assert.Error(t, err)
+
+	client, err := deepseek.NewFailoverClient(deepseek.Endpoint{Client: deepseek.NewClient("key")})
+	require.NoError(t, err)
+	stats := client.Stats()
+	assert.Equal(t, "https://api.deepseek.com/", stats[0].Name)
+	assert.Equal(t, 1, stats[0].Weight)
+}
+
+func TestFailoverClient_CancelledTrialKeepsCircuit(t *testing.T) {
+	flakySrv, flaky := newFailoverEndpoint(t, "flaky", 1)
+	flakySrv.SetDefault(deepseektest.RouteChat, deepseektest.Response{Status: http.StatusInternalServerError})
+	client := newOrderedFailoverClient(t, flaky)
+	client.FailureThreshold = 1
+	client.Cooldown = 50 * time.Millisecond
+
+	_, err := client.CreateChatCompletion(context.Background(), testChatRequest())
+	require.Error(t, err)
+	time.Sleep(100 * time.Millisecond)
+	require.Equal(t, deepseek.CircuitHalfOpen, client.Stats()[0].State)
+
+	// The caller gives up during the trial request.
+	flakySrv.Enqueue(deepseektest.RouteChat, deepseektest.Response{Latency: time.Second})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = client.CreateChatCompletion(ctx, testChatRequest())
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	stats := client.Stats()
+	assert.Equal(t, deepseek.CircuitHalfOpen, stats[0].State, "a cancelled trial neither closes nor opens the circuit")
+	assert.Equal(t, 1, stats[0].Failures)
+
+	// The trial is free again.
+	flakySrv.SetDefault(deepseektest.RouteChat, deepseektest.Response{Content: "recovered"})
+	_, err = client.CreateChatCompletion(context.Background(), testChatRequest())
+	require.NoError(t, err)
+	assert.Equal(t, deepseek.CircuitClosed, client.Stats()[0].State)
+}
@@ -1,13 +1,10 @@
 package deepseek
 
 import (
-	"bufio"
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
-	"strings"
 )
 
 // FIMCompletionRequest represents the request body for a Fill-In-the-Middle (FIM) completion.
@@ -28,21 +25,20 @@ type FIMCompletionRequest struct {
 
 // FIMCompletionResponse represents the response body for a Fill-In-the-Middle (FIM) completion.
 type FIMCompletionResponse struct {
-	ID      string `json:"id"`      // Unique ID for the completion.
-	Object  string `json:"object"`  // The object type, e.g., "text_completion".
-	Created int    `json:"created"` // Timestamp of when the completion was created.
-	Model   string `json:"model"`   // Model used for the completion.
-	Choices []struct {
-		Text         string   `json:"text"`          // The generated completion text.
-		Index        int      `json:"index"`         // Index of the choice.
-		Logprobs     Logprobs `json:"logprobs"`      // Log probabilities of the generated tokens (if requested).
-		FinishReason string   `json:"finish_reason"` // Reason for finishing the completion, e.g., "stop", "length".
-	} `json:"choices"`
-	Usage struct {
-		PromptTokens     int `json:"prompt_tokens"`     // Number of tokens in the prompt.
-		CompletionTokens int `json:"completion_tokens"` // Number of tokens in the completion.
-		TotalTokens      int `json:"total_tokens"`      // Total number of tokens used.
-	} `json:"usage"`
+	ID      string      `json:"id"`      // Unique ID for the completion.
+	Object  string      `json:"object"`  // The object type, e.g., "text_completion".
+	Created int         `json:"created"` // Timestamp of when the completion was created.
+	Model   string      `json:"model"`   // Model used for the completion.
+	Choices []FIMChoice `json:"choices"` // The generated completions.
+	Usage   Usage       `json:"usage"`   // Token usage statistics.
+}
+
+// FIMChoice represents a single choice of a Fill-In-the-Middle (FIM) completion.
+type FIMChoice struct {
+	Text         string       `json:"text"`          // The generated completion text.
+	Index        int          `json:"index"`         // Index of the choice.
+	Logprobs     Logprobs     `json:"logprobs"`      // Log probabilities of the generated tokens (if requested).
+	FinishReason FinishReason `json:"finish_reason"` // Reason for finishing the completion, e.g., FinishReasonStop.
 }
 
 // FIMStreamCompletionRequest represents the request body for a streaming Fill-In-the-Middle (FIM) completion.
@@ -71,9 +67,9 @@ type FIMStreamChoice struct {
 	// Index of this choice within the list of choices.
 	Index int `json:"index"`
 	// Log probabilities for the generated tokens (if available).  May be `nil`.
-	Logprobs Logprobs `json:"logprobs,omitempty"`
-	// Reason why the generation finished (e.g., "stop", "length"). May be `nil`.
-	FinishReason interface{} `json:"finish_reason,omitempty"`
+	Logprobs *Logprobs `json:"logprobs,omitempty"`
+	// Reason why the generation finished (e.g., FinishReasonStop). `nil` until the last chunk.
+	FinishReason *FinishReason `json:"finish_reason,omitempty"`
 }
 
 // FIMStreamCompletionResponse represents the full response body for a streaming Fill-In-the-Middle (FIM) completion.
@@ -95,49 +91,40 @@ type FIMStreamCompletionResponse struct {
 	Usage *Usage `json:"usage,omitempty"`
 }
 
-// fimCompletionStream implements the ChatCompletionStream interface.
+// fimCompletionStream implements the FIMChatCompletionStream interface.
 type fimCompletionStream struct {
 	ctx    context.Context    // Context for cancellation.
 	cancel context.CancelFunc // Cancel function for the context.
 	resp   *http.Response     // HTTP response from the API call.
-	reader *bufio.Reader      // Reader for the response body.
+	reader *streamReader      // Reader for the events of the response body.
 }
 
-// FIMChatCompletionStream is an interface for receiving streaming chat completion responses.
+// FIMChatCompletionStream is an interface for receiving streaming FIM completion responses.
+// It is a Stream, so it can be used like a ChatCompletionStream.
 type FIMChatCompletionStream interface {
+	Stream[*FIMStreamCompletionResponse]
+
+	// Deprecated: Use Recv.
 	FIMRecv() (*FIMStreamCompletionResponse, error)
+	// Deprecated: Use Close.
 	FIMClose() error
 }
 
-// FIMRecv receives the next response from the stream.
-func (s *fimCompletionStream) FIMRecv() (*FIMStreamCompletionResponse, error) {
-	reader := s.reader
-	for {
-		line, err := reader.ReadString('\n') // Read until newline
-		if err != nil {
-			if err == io.EOF {
-				return nil, io.EOF
-			}
-			return nil, fmt.Errorf("error reading stream: %w", err)
-		}
-
-		line = strings.TrimSpace(line)
-		if line == "data: [DONE]" {
-			return nil, io.EOF // End of stream
-		}
-		if len(line) > 6 && line[:6] == "data: " {
-			trimmed := line[6:] // Trim the "data: " prefix
-			var response FIMStreamCompletionResponse
-			if err := json.Unmarshal([]byte(trimmed), &response); err != nil {
-				return nil, fmt.Errorf("unmarshal error: %w, raw data: %s", err, trimmed)
-			}
-			return &response, nil
-		}
+// Recv receives the next response from the stream.
+func (s *fimCompletionStream) Recv() (*FIMStreamCompletionResponse, error) {
+	data, err := s.reader.next()
+	if err != nil {
+		return nil, err
 	}
+	var response FIMStreamCompletionResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("unmarshal error: %w, raw data: %s", err, data)
+	}
+	return &response, nil
 }
 
-// FIMClose terminates the stream.
-func (s *fimCompletionStream) FIMClose() error {
+// Close terminates the stream.
+func (s *fimCompletionStream) Close() error {
 	s.cancel()
 	err := s.resp.Body.Close()
 	if err != nil {
@@ -145,3 +132,17 @@ func (s *fimCompletionStream) FIMClose() error {
 	}
 	return nil
 }
+
+// FIMRecv receives the next response from the stream.
+//
+// Deprecated: Use Recv.
+func (s *fimCompletionStream) FIMRecv() (*FIMStreamCompletionResponse, error) {
+	return s.Recv()
+}
+
+// FIMClose terminates the stream.
+//
+// Deprecated: Use Close.
+func (s *fimCompletionStream) FIMClose() error {
+	return s.Close()
+}
@@ -2,10 +2,14 @@ package deepseek_test
 
 import (
 	"context"
+	"errors"
+	"io"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/cohesion-org/deepseek-go"
+	"github.com/cohesion-org/deepseek-go/deepseektest"
 	"github.com/cohesion-org/deepseek-go/internal/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -13,8 +17,7 @@ import (
 
 func TestCreateFIMCompletion(t *testing.T) {
 	testutil.SkipIfShort(t)
-	config := testutil.LoadTestConfig(t)
-	client := deepseek.NewClient(config.APIKey)
+	client, config := testutil.NewCassetteClient(t, "fim_completion")
 
 	tests := []struct {
 		name        string
@@ -94,8 +97,7 @@ func TestCreateFIMCompletion(t *testing.T) {
 
 func TestCreateFIMCompletionWithParameters(t *testing.T) {
 	testutil.SkipIfShort(t)
-	config := testutil.LoadTestConfig(t)
-	client := deepseek.NewClient(config.APIKey)
+	client, config := testutil.NewCassetteClient(t, "fim_completion_parameters")
 
 	tests := []struct {
 		name        string
@@ -161,8 +163,7 @@ func TestCreateFIMCompletionWithParameters(t *testing.T) {
 
 func TestFIMCompletionResponseStructure(t *testing.T) {
 	testutil.SkipIfShort(t)
-	config := testutil.LoadTestConfig(t)
-	client := deepseek.NewClient(config.APIKey)
+	client, config := testutil.NewCassetteClient(t, "fim_response_structure")
 
 	req := &deepseek.FIMCompletionRequest{
 		Model:  deepseek.DeepSeekChat,
@@ -248,3 +249,67 @@ func TestFIMCompletionResponseStructure(t *testing.T) {
 // 		assert.NotEmpty(t, choice.FinishReason)
 // 	}
 // }
+
+func TestCreateFIMCompletion_Offline(t *testing.T) {
+	srv, client := newTestServer(t)
+	srv.Enqueue(deepseektest.RouteFIM, deepseektest.Response{Content: "return a + b", FinishReason: deepseek.FinishReasonLength})
+
+	resp, err := client.CreateFIMCompletion(context.Background(), &deepseek.FIMCompletionRequest{
+		Model:  deepseek.DeepSeekChat,
+		Prompt: "def add(a, b):",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "text_completion", resp.Object)
+	assert.Equal(t, "return a + b", resp.Choices[0].Text)
+	assert.Equal(t, deepseek.FinishReasonLength, resp.Choices[0].FinishReason)
+
+	_, err = client.CreateFIMCompletion(context.Background(), &deepseek.FIMCompletionRequest{
+		Model:     deepseek.DeepSeekChat,
+		Prompt:    "def add(a, b):",
+		MaxTokens: 5000,
+	})
+	assert.Error(t, err)
+	assert.Len(t, srv.Requests(), 1, "invalid requests should not be sent")
+}
+
+func TestCreateFIMStreamCompletion_KeepAlive(t *testing.T) {
+	srv, client := newTestServer(t)
+	srv.Enqueue(deepseektest.RouteFIM, deepseektest.Response{Content: "return a + b", KeepAlive: true})
+
+	stream, err := client.CreateFIMStreamCompletion(context.Background(), &deepseek.FIMStreamCompletionRequest{
+		Model:  deepseek.DeepSeekChat,
+		Prompt: "def add(a, b):",
+	})
+	require.NoError(t, err)
+	defer stream.Close()
+
+	var text string
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		for _, choice := range chunk.Choices {
+			text += choice.Text
+		}
+	}
+	assert.Equal(t, "return a + b", text)
+}
+
+func TestCreateFIMStreamCompletion_IdleTimeout(t *testing.T) {
+	srv, client := newTestServer(t, deepseek.WithStreamIdleTimeout(50*time.Millisecond))
+	srv.Enqueue(deepseektest.RouteFIM, deepseektest.Response{Chunks: []string{"return", " a + b"}, ChunkDelay: time.Second})
+
+	stream, err := client.CreateFIMStreamCompletion(context.Background(), &deepseek.FIMStreamCompletionRequest{
+		Model:  deepseek.DeepSeekChat,
+		Prompt: "def add(a, b):",
+	})
+	require.NoError(t, err)
+	defer stream.Close()
+
+	_, err = stream.Recv()
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.ErrorIs(t, err, deepseek.ErrStreamIdleTimeout)
+}
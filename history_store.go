@@ -0,0 +1,279 @@
+package deepseek
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HistorySchemaVersion is the version of the files written by FileHistoryStore.
+// It is raised when the format changes; files of older versions are still read.
+const HistorySchemaVersion = 1
+
+var (
+	// ErrHistoryNotFound is returned by HistoryStore.Load for an unknown conversation ID.
+	ErrHistoryNotFound = errors.New("conversation history not found")
+	// ErrUnsupportedHistoryVersion is returned for a history file with a schema version this library
+	// cannot read, e.g. one written by a newer release.
+	ErrUnsupportedHistoryVersion = errors.New("unsupported history schema version")
+)
+
+// HistoryTurn is a turn of a stored conversation: the messages sent with it, the reply, and its metadata.
+type HistoryTurn struct {
+	Messages   []ChatCompletionMessage `json:"messages"`              // Messages of the turn, the reply last.
+	Model      string                  `json:"model,omitempty"`       // Model that replied.
+	Usage      *Usage                  `json:"usage,omitempty"`       // Token usage of the turn.
+	ResponseID string                  `json:"response_id,omitempty"` // ID of the chat completion.
+	CreatedAt  time.Time               `json:"created_at"`            // Time the turn was recorded.
+}
+
+// HistoryStore persists the turns of conversations by conversation ID.
+// Implementations must be safe for concurrent use.
+type HistoryStore interface {
+	// Load returns the turns of a conversation, oldest first, or ErrHistoryNotFound.
+	Load(ctx context.Context, id string) ([]HistoryTurn, error)
+	// Append adds turns to a conversation, creating it if needed.
+	Append(ctx context.Context, id string, turns ...HistoryTurn) error
+	// List returns the IDs of the stored conversations, sorted.
+	List(ctx context.Context) ([]string, error)
+	// Delete removes a conversation. Deleting an unknown ID is not an error.
+	Delete(ctx context.Context, id string) error
+}
+
+// HistoryMessages returns the messages of turns in order, e.g. to restore a conversation.
+func HistoryMessages(turns []HistoryTurn) []ChatCompletionMessage {
+	var messages []ChatCompletionMessage
+	for _, turn := range turns {
+		messages = append(messages, turn.Messages...)
+	}
+	return messages
+}
+
+// MemoryHistoryStore is a HistoryStore that keeps conversations in memory, e.g. for tests.
+type MemoryHistoryStore struct {
+	mu            sync.Mutex
+	conversations map[string][]HistoryTurn
+}
+
+// NewMemoryHistoryStore creates an empty MemoryHistoryStore.
+func NewMemoryHistoryStore() *MemoryHistoryStore {
+	return &MemoryHistoryStore{conversations: make(map[string][]HistoryTurn)}
+}
+
+// Load implements HistoryStore.
+func (s *MemoryHistoryStore) Load(_ context.Context, id string) ([]HistoryTurn, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	turns, ok := s.conversations[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrHistoryNotFound, id)
+	}
+	return copyTurns(turns), nil
+}
+
+// Append implements HistoryStore.
+func (s *MemoryHistoryStore) Append(_ context.Context, id string, turns ...HistoryTurn) error {
+	if id == "" {
+		return fmt.Errorf("conversation ID cannot be empty")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.conversations[id] = append(s.conversations[id], copyTurns(turns)...)
+	return nil
+}
+
+// List implements HistoryStore.
+func (s *MemoryHistoryStore) List(_ context.Context) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids := make([]string, 0, len(s.conversations))
+	for id := range s.conversations {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids, nil
+}
+
+// Delete implements HistoryStore.
+func (s *MemoryHistoryStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.conversations, id)
+	return nil
+}
+
+// copyTurns copies turns, so that the store does not share messages with its callers.
+func copyTurns(turns []HistoryTurn) []HistoryTurn {
+	copied := make([]HistoryTurn, len(turns))
+	for i, turn := range turns {
+		turn.Messages = slices.Clone(turn.Messages)
+		for j := range turn.Messages {
+			turn.Messages[j].ToolCalls = slices.Clone(turn.Messages[j].ToolCalls)
+		}
+		if turn.Usage != nil {
+			usage := *turn.Usage
+			turn.Usage = &usage
+		}
+		copied[i] = turn
+	}
+	return copied
+}
+
+// historyFile is the on-disk format of a conversation in a FileHistoryStore.
+type historyFile struct {
+	Version        int           `json:"version"`
+	ConversationID string        `json:"conversation_id"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+	Turns          []HistoryTurn `json:"turns"`
+}
+
+// FileHistoryStore is a HistoryStore that keeps each conversation in a JSON file in Dir.
+//
+// Files are versioned with HistorySchemaVersion and replaced atomically on every Append.
+// Conversation IDs are escaped in file names, so any non-empty ID can be used.
+type FileHistoryStore struct {
+	Dir string // Directory of the conversation files.
+
+	mu sync.Mutex
+}
+
+// NewFileHistoryStore creates a FileHistoryStore in dir, creating the directory if needed.
+func NewFileHistoryStore(dir string) (*FileHistoryStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("creating history directory: %w", err)
+	}
+	return &FileHistoryStore{Dir: dir}, nil
+}
+
+const historyFileExt = ".json"
+
+// path returns the file of the conversation id.
+func (s *FileHistoryStore) path(id string) (string, error) {
+	if id == "" {
+		return "", fmt.Errorf("conversation ID cannot be empty")
+	}
+	return filepath.Join(s.Dir, url.PathEscape(id)+historyFileExt), nil
+}
+
+// Load implements HistoryStore.
+func (s *FileHistoryStore) Load(_ context.Context, id string) ([]HistoryTurn, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	file, err := s.read(id)
+	if err != nil {
+		return nil, err
+	}
+	return file.Turns, nil
+}
+
+// Append implements HistoryStore.
+func (s *FileHistoryStore) Append(_ context.Context, id string, turns ...HistoryTurn) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	file, err := s.read(id)
+	if errors.Is(err, ErrHistoryNotFound) {
+		file, err = &historyFile{ConversationID: id}, nil
+	}
+	if err != nil {
+		return err
+	}
+	file.Version = HistorySchemaVersion
+	file.UpdatedAt = time.Now().UTC()
+	file.Turns = append(file.Turns, turns...)
+	return s.write(id, file)
+}
+
+// List implements HistoryStore.
+func (s *FileHistoryStore) List(_ context.Context) ([]string, error) {
+	entries, err := os.ReadDir(s.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("listing histories: %w", err)
+	}
+	var ids []string
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), historyFileExt)
+		if !ok || entry.IsDir() {
+			continue
+		}
+		id, err := url.PathUnescape(name)
+		if err != nil {
+			continue // Not written by the store.
+		}
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids, nil
+}
+
+// Delete implements HistoryStore.
+func (s *FileHistoryStore) Delete(_ context.Context, id string) error {
+	path, err := s.path(id)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("deleting history: %w", err)
+	}
+	return nil
+}
+
+// read reads and decodes the file of the conversation id.
+func (s *FileHistoryStore) read(id string) (*historyFile, error) {
+	path, err := s.path(id)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrHistoryNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading history: %w", err)
+	}
+	var file historyFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("decoding history %s: %w", path, err)
+	}
+	if file.Version < 1 || file.Version > HistorySchemaVersion {
+		return nil, fmt.Errorf("%w: %s has version %d, this library reads up to %d", ErrUnsupportedHistoryVersion, path, file.Version, HistorySchemaVersion)
+	}
+	return &file, nil
+}
+
+// write replaces the file of the conversation id with file.
+func (s *FileHistoryStore) write(id string, file *historyFile) error {
+	path, err := s.path(id)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding history: %w", err)
+	}
+	tmp, err := os.CreateTemp(s.Dir, ".history-*")
+	if err != nil {
+		return fmt.Errorf("writing history: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // Fails harmlessly once the file was renamed.
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing history: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing history: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("writing history: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package deepseek_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cohesion-org/deepseek-go"
+	"github.com/cohesion-org/deepseek-go/deepseektest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testTurn() deepseek.HistoryTurn {
+	call := toolCallMessage("call_1")
+	call.ReasoningContent = "Need to look it up."
+	return deepseek.HistoryTurn{
+		Messages: []deepseek.ChatCompletionMessage{
+			message(deepseek.ChatMessageRoleUser, "u1"),
+			call,
+			toolResult("call_1"),
+			{Role: deepseek.ChatMessageRoleAssistant, Content: "a1", Prefix: true},
+		},
+		Model:      deepseek.DeepSeekReasoner,
+		Usage:      &deepseek.Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+		ResponseID: "resp_1",
+		CreatedAt:  time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+}
+
+func testHistoryStore(t *testing.T, store deepseek.HistoryStore) {
+	ctx := context.Background()
+
+	_, err := store.Load(ctx, "chat/1")
+	require.ErrorIs(t, err, deepseek.ErrHistoryNotFound)
+
+	require.NoError(t, store.Append(ctx, "chat/1", testTurn()))
+	require.NoError(t, store.Append(ctx, "chat/1", deepseek.HistoryTurn{Messages: testHistory()[:2]}))
+	require.NoError(t, store.Append(ctx, "other", testTurn()))
+	assert.Error(t, store.Append(ctx, "", testTurn()))
+
+	turns, err := store.Load(ctx, "chat/1")
+	require.NoError(t, err)
+	require.Len(t, turns, 2)
+	assert.Equal(t, testTurn(), turns[0])
+	assert.Len(t, deepseek.HistoryMessages(turns), 6)
+
+	ids, err := store.List(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"chat/1", "other"}, ids)
+
+	require.NoError(t, store.Delete(ctx, "chat/1"))
+	require.NoError(t, store.Delete(ctx, "chat/1"))
+	_, err = store.Load(ctx, "chat/1")
+	assert.ErrorIs(t, err, deepseek.ErrHistoryNotFound)
+	ids, err = store.List(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"other"}, ids)
+}
+
+func TestMemoryHistoryStore(t *testing.T) {
+	testHistoryStore(t, deepseek.NewMemoryHistoryStore())
+}
+
+func TestFileHistoryStore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "histories")
+	store, err := deepseek.NewFileHistoryStore(dir)
+	require.NoError(t, err)
+	testHistoryStore(t, store)
+
+	// The files are read by a new store, e.g. after a restart.
+	reopened, err := deepseek.NewFileHistoryStore(dir)
+	require.NoError(t, err)
+	loaded, err := reopened.Load(context.Background(), "other")
+	require.NoError(t, err)
+	assert.Equal(t, []deepseek.HistoryTurn{testTurn()}, loaded)
+}
+
+func TestFileHistoryStore_Version(t *testing.T) {
+	dir := t.TempDir()
+	store, err := deepseek.NewFileHistoryStore(dir)
+	require.NoError(t, err)
+	require.NoError(t, store.Append(context.Background(), "chat", testTurn()))
+
+	data, err := os.ReadFile(filepath.Join(dir, "chat.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"version": 1`)
+	assert.Contains(t, string(data), `"reasoning_content": "Need to look it up."`)
+
+	future := `{"version": 99, "conversation_id": "future", "turns": []}`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "future.json"), []byte(future), 0o600))
+	_, err = store.Load(context.Background(), "future")
+	assert.ErrorIs(t, err, deepseek.ErrUnsupportedHistoryVersion)
+	assert.ErrorIs(t, store.Append(context.Background(), "future", testTurn()), deepseek.ErrUnsupportedHistoryVersion)
+}
+
+func TestConversation_Store(t *testing.T) {
+	_, conv := newTestConversation(t,
+		deepseektest.Response{Content: "Everest.", ReasoningContent: "Easy.", Model: deepseek.DeepSeekReasoner},
+		deepseektest.Response{Content: "K2."},
+	)
+	store := deepseek.NewMemoryHistoryStore()
+	conv.Store, conv.ID = store, "chat"
+
+	_, err := conv.Send(context.Background(), "Tallest mountain?")
+	require.NoError(t, err)
+	conv.Append(message(deepseek.ChatMessageRoleUser, "Answer briefly."))
+	_, err = conv.Send(context.Background(), "And the second?")
+	require.NoError(t, err)
+
+	turns, err := store.Load(context.Background(), "chat")
+	require.NoError(t, err)
+	require.Len(t, turns, 2)
+	assert.Equal(t, []string{"Be brief.", "Tallest mountain?", "Everest."}, contents(turns[0].Messages))
+	assert.Equal(t, "Easy.", turns[0].Messages[2].ReasoningContent)
+	assert.Equal(t, []string{"Answer briefly.", "And the second?", "K2."}, contents(turns[1].Messages))
+	assert.Equal(t, deepseek.DeepSeekReasoner, turns[0].Model)
+	require.NotNil(t, turns[0].Usage)
+	assert.NotZero(t, turns[0].Usage.TotalTokens)
+	assert.NotEmpty(t, turns[0].ResponseID)
+
+	restored := deepseek.NewConversation(conv.Client, deepseek.DeepSeekReasoner, "")
+	restored.Store, restored.ID = store, "chat"
+	require.NoError(t, restored.Load(context.Background()))
+	assert.Equal(t, conv.Messages(), restored.Messages())
+
+	restored.ID = "unknown"
+	assert.ErrorIs(t, restored.Load(context.Background()), deepseek.ErrHistoryNotFound)
+	assert.Len(t, restored.Messages(), 6)
+}
@@ -0,0 +1,177 @@
+// Package sse decodes server-sent event streams as specified by the HTML Living Standard
+// (https://html.spec.whatwg.org/multipage/server-sent-events.html#event-stream-interpretation).
+//
+// It is used by the chat and FIM streams of the deepseek package.
+package sse
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxLineSize is the longest line the Decoder accepts. Longer lines fail with bufio.ErrTooLong.
+const MaxLineSize = 8 << 20
+
+// DefaultEventType is the type of events that have no "event" field.
+const DefaultEventType = "message"
+
+// Event is a dispatched server-sent event.
+type Event struct {
+	ID    string        // Last event ID seen on the stream, which persists across events.
+	Type  string        // Value of the "event" field, DefaultEventType if absent.
+	Data  string        // Values of the "data" fields, joined with "\n".
+	Retry time.Duration // Value of the last valid "retry" field, 0 if none was received.
+}
+
+// Decoder reads events from an event stream. It is not safe for concurrent use.
+type Decoder struct {
+	scanner   *bufio.Scanner
+	started   bool
+	lastID    string
+	retry     time.Duration
+	eventType string
+	data      strings.Builder
+	hasData   bool
+	cutOff    bool // Whether the last line ended with the stream instead of a line ending.
+}
+
+// NewDecoder returns a Decoder reading from r.
+func NewDecoder(r io.Reader) *Decoder {
+	d := &Decoder{scanner: bufio.NewScanner(r)}
+	d.scanner.Buffer(make([]byte, 0, 4096), MaxLineSize)
+	d.scanner.Split(d.scanLines)
+	return d
+}
+
+// Next returns the next event. Comments and events without data are skipped.
+// It returns io.EOF when the stream ends.
+//
+// Unlike the spec, which discards an event that is not terminated by a blank line, Next
+// dispatches the complete lines of such an event when the stream ends, like most clients do,
+// so that a final "data: [DONE]\n" is not lost. A last line cut off without a line ending
+// is discarded.
+func (d *Decoder) Next() (*Event, error) {
+	for d.scanner.Scan() {
+		if d.cutOff {
+			break
+		}
+		line := d.scanner.Bytes()
+		if !d.started {
+			d.started = true
+			line = bytes.TrimPrefix(line, []byte("\uFEFF"))
+		}
+		if len(line) == 0 {
+			if event := d.dispatch(); event != nil {
+				return event, nil
+			}
+			continue
+		}
+		d.processLine(line)
+	}
+	if err := d.scanner.Err(); err != nil {
+		return nil, err
+	}
+	if event := d.dispatch(); event != nil {
+		return event, nil
+	}
+	return nil, io.EOF
+}
+
+func (d *Decoder) processLine(line []byte) {
+	if line[0] == ':' {
+		return // Comment, such as ": keep-alive".
+	}
+	field, value := line, []byte(nil)
+	if i := bytes.IndexByte(line, ':'); i >= 0 {
+		field, value = line[:i], line[i+1:]
+		value = bytes.TrimPrefix(value, []byte(" "))
+	}
+	switch string(field) {
+	case "event":
+		d.eventType = string(value)
+	case "data":
+		d.data.Write(value)
+		d.data.WriteByte('\n')
+		d.hasData = true
+	case "id":
+		if bytes.IndexByte(value, 0) < 0 {
+			d.lastID = string(value)
+		}
+	case "retry":
+		if ms, ok := parseDigits(value); ok {
+			d.retry = time.Duration(ms) * time.Millisecond
+		}
+	}
+}
+
+func (d *Decoder) dispatch() *Event {
+	defer func() {
+		d.eventType = ""
+		d.data.Reset()
+		d.hasData = false
+	}()
+	if !d.hasData {
+		return nil
+	}
+	event := &Event{
+		ID:    d.lastID,
+		Type:  d.eventType,
+		Data:  strings.TrimSuffix(d.data.String(), "\n"),
+		Retry: d.retry,
+	}
+	if event.Type == "" {
+		event.Type = DefaultEventType
+	}
+	return event
+}
+
+// parseDigits parses a retry value, which must consist of ASCII digits only.
+func parseDigits(value []byte) (int64, bool) {
+	if len(value) == 0 {
+		return 0, false
+	}
+	for _, c := range value {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+	}
+	n, err := strconv.ParseInt(string(value), 10, 64)
+	if err != nil || n > int64(math.MaxInt64/time.Millisecond) {
+		return 0, false
+	}
+	return n, true
+}
+
+// scanLines is a bufio.SplitFunc for lines ending in "\r\n", "\n" or "\r".
+// A trailing "\r" waits for the next byte, so that a "\r\n" split across reads
+// is not mistaken for two line endings. A last line without a line ending sets cutOff.
+func (d *Decoder) scanLines(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		if data[i] == '\n' {
+			return i + 1, data[:i], nil
+		}
+		if i+1 < len(data) {
+			if data[i+1] == '\n' {
+				return i + 2, data[:i], nil
+			}
+			return i + 1, data[:i], nil
+		}
+		if atEOF {
+			return i + 1, data[:i], nil
+		}
+		return 0, nil, nil
+	}
+	if atEOF {
+		d.cutOff = true
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
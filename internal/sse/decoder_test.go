@@ -0,0 +1,185 @@
+package sse_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/cohesion-org/deepseek-go/internal/sse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeAll(t *testing.T, r io.Reader) []sse.Event {
+	t.Helper()
+	decoder := sse.NewDecoder(r)
+	var events []sse.Event
+	for {
+		event, err := decoder.Next()
+		if errors.Is(err, io.EOF) {
+			return events
+		}
+		require.NoError(t, err)
+		events = append(events, *event)
+	}
+}
+
+func TestDecoder(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []sse.Event
+	}{
+		{
+			name:  "data with and without space",
+			input: "data: {\"a\":1}\n\ndata:{\"b\":2}\n\n",
+			want:  []sse.Event{{Type: "message", Data: `{"a":1}`}, {Type: "message", Data: `{"b":2}`}},
+		},
+		{
+			name:  "multi-line data",
+			input: "data: first\ndata: second\ndata\n\n",
+			want:  []sse.Event{{Type: "message", Data: "first\nsecond\n"}},
+		},
+		{
+			name:  "comments and keep-alives",
+			input: ": keep-alive\n\n:\ndata: x\n: in between\n\n",
+			want:  []sse.Event{{Type: "message", Data: "x"}},
+		},
+		{
+			name:  "event type is reset after dispatch",
+			input: "event: error\ndata: boom\n\ndata: ok\n\n",
+			want:  []sse.Event{{Type: "error", Data: "boom"}, {Type: "message", Data: "ok"}},
+		},
+		{
+			name:  "event without data is not dispatched",
+			input: "event: ping\n\ndata: ok\n\n",
+			want:  []sse.Event{{Type: "message", Data: "ok"}},
+		},
+		{
+			name:  "empty data is dispatched",
+			input: "data:\n\n",
+			want:  []sse.Event{{Type: "message", Data: ""}},
+		},
+		{
+			name:  "id persists and retry",
+			input: "id: 7\nretry: 1500\ndata: a\n\nretry: soon\ndata: b\n\nid\ndata: c\n\n",
+			want: []sse.Event{
+				{ID: "7", Type: "message", Data: "a", Retry: 1500 * time.Millisecond},
+				{ID: "7", Type: "message", Data: "b", Retry: 1500 * time.Millisecond},
+				{ID: "", Type: "message", Data: "c", Retry: 1500 * time.Millisecond},
+			},
+		},
+		{
+			name:  "CRLF and CR line endings",
+			input: "data: a\r\n\r\ndata: b\r\rdata: c\n\n",
+			want:  []sse.Event{{Type: "message", Data: "a"}, {Type: "message", Data: "b"}, {Type: "message", Data: "c"}},
+		},
+		{
+			name:  "leading BOM and only one space stripped",
+			input: "\uFEFFdata:  two spaces\n\n",
+			want:  []sse.Event{{Type: "message", Data: " two spaces"}},
+		},
+		{
+			name:  "unknown fields are ignored",
+			input: "foo: bar\ndata: x\n\n",
+			want:  []sse.Event{{Type: "message", Data: "x"}},
+		},
+		{
+			name:  "event without a blank line is dispatched at the end",
+			input: "data: a\n\ndata: [DONE]\n",
+			want:  []sse.Event{{Type: "message", Data: "a"}, {Type: "message", Data: "[DONE]"}},
+		},
+		{
+			name:  "line cut off by the end is discarded",
+			input: "data: a\n\ndata: b\ndata: part",
+			want:  []sse.Event{{Type: "message", Data: "a"}, {Type: "message", Data: "b"}},
+		},
+		{
+			name:  "line cut off alone is discarded",
+			input: "data: a\n\ndata: part",
+			want:  []sse.Event{{Type: "message", Data: "a"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, decodeAll(t, strings.NewReader(tt.input)))
+			// Reading one byte at a time splits every line ending across reads.
+			assert.Equal(t, tt.want, decodeAll(t, iotest.OneByteReader(strings.NewReader(tt.input))))
+		})
+	}
+}
+
+func TestDecoder_ReadError(t *testing.T) {
+	boom := errors.New("connection reset")
+	decoder := sse.NewDecoder(io.MultiReader(strings.NewReader("data: a\n\ndata: b\n"), iotest.ErrReader(boom)))
+
+	event, err := decoder.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "a", event.Data)
+
+	_, err = decoder.Next()
+	assert.ErrorIs(t, err, boom)
+}
+
+func FuzzDecoder(f *testing.F) {
+	f.Add([]byte("data: {\"a\":1}\n\n"))
+	f.Add([]byte("event: error\ndata: {\"error\":{\"message\":\"x\"}}\n\n"))
+	f.Add([]byte(": keep-alive\r\ndata:x\r\n\r\n"))
+	f.Add([]byte("id: 1\nretry: 99999999999999999999\ndata\n\n"))
+	f.Add([]byte("\uFEFFdata: a\rdata: b\r\r"))
+
+	f.Fuzz(func(t *testing.T, input []byte) {
+		decoder := sse.NewDecoder(strings.NewReader(string(input)))
+		for i := 0; ; i++ {
+			event, err := decoder.Next()
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if event.Type == "" {
+				t.Fatal("dispatched event without a type")
+			}
+			if strings.ContainsAny(event.Type, "\r\n") || strings.ContainsAny(event.Data, "\r") {
+				t.Fatalf("line breaks leaked into the event: %q", event)
+			}
+			if i > len(input) {
+				t.Fatal("more events than input bytes")
+			}
+		}
+	})
+}
+
+func FuzzDecoder_RoundTrip(f *testing.F) {
+	f.Add("error", "{\"error\":{\"message\":\"overloaded\"}}")
+	f.Add("", "line one\nline two")
+	f.Add("message", "")
+
+	f.Fuzz(func(t *testing.T, eventType, data string) {
+		if strings.ContainsAny(eventType, "\r\n") || strings.ContainsRune(data, '\r') {
+			t.Skip("line breaks cannot be encoded in these fields")
+		}
+
+		var sb strings.Builder
+		sb.WriteString(": keep-alive\n\n")
+		if eventType != "" {
+			sb.WriteString("event: " + eventType + "\n")
+		}
+		for _, line := range strings.Split(data, "\n") {
+			sb.WriteString("data: " + line + "\n")
+		}
+		sb.WriteString("\n")
+
+		events := decodeAll(t, strings.NewReader(sb.String()))
+		require.Len(t, events, 1)
+		if eventType == "" {
+			eventType = sse.DefaultEventType
+		}
+		assert.Equal(t, eventType, events[0].Type)
+		assert.Equal(t, data, events[0].Data)
+	})
+}
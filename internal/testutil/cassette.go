@@ -0,0 +1,78 @@
+package testutil
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cohesion-org/deepseek-go"
+	"github.com/cohesion-org/deepseek-go/deepseektest"
+)
+
+// CassetteDir is where recorded API exchanges are stored, relative to the package under test.
+const CassetteDir = "testdata/cassettes"
+
+// NewCassetteClient returns a client for integration tests backed by the named cassette.
+//
+//   - With TEST_DEEPSEEK_RECORD=1 and an API key, real exchanges are recorded to the cassette.
+//   - Otherwise, if the cassette exists, it is replayed and no API key is needed.
+//   - Otherwise, with TEST_DEEPSEEK_REPLAY=1, the test fails, so that CI notices missing cassettes.
+//   - Otherwise the test talks to the real API, or is skipped if no API key is set.
+func NewCassetteClient(t *testing.T, name string) (*deepseek.Client, *TestConfig) {
+	t.Helper()
+
+	file := filepath.Join(CassetteDir, name+".json")
+	_, statErr := os.Stat(file)
+	record := os.Getenv("TEST_DEEPSEEK_RECORD") == "1"
+	replay := os.Getenv("TEST_DEEPSEEK_REPLAY") == "1"
+	if record && replay {
+		t.Fatalf("TEST_DEEPSEEK_RECORD and TEST_DEEPSEEK_REPLAY cannot both be set")
+	}
+	if replay && statErr != nil {
+		t.Fatalf("cassette %s is missing, record it with TEST_DEEPSEEK_RECORD=1: %v", file, statErr)
+	}
+
+	if !record && statErr == nil {
+		recorder, err := deepseektest.NewRecorder(file, deepseektest.ModeReplay, nil)
+		if err != nil {
+			t.Fatalf("failed to load cassette: %v", err)
+		}
+		config := &TestConfig{APIKey: "replay", TestTimeout: defaultTestTimeout()}
+		return newClient(t, config.APIKey, recorder), config
+	}
+
+	config := LoadTestConfig(t)
+	if !record {
+		return deepseek.NewClient(config.APIKey), config
+	}
+
+	recorder, err := deepseektest.NewRecorder(file, deepseektest.ModeRecord, nil)
+	if err != nil {
+		t.Fatalf("failed to create recorder: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := recorder.Save(); err != nil {
+			t.Errorf("failed to save cassette: %v", err)
+			return
+		}
+		// The Authorization header is scrubbed by the recorder; make sure the key did not leak elsewhere.
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Errorf("failed to read cassette: %v", err)
+		} else if bytes.Contains(data, []byte(config.APIKey)) {
+			os.Remove(file)
+			t.Errorf("cassette %s contained the API key and was removed", file)
+		}
+	})
+	return newClient(t, config.APIKey, recorder), config
+}
+
+func newClient(t *testing.T, apiKey string, doer deepseek.HTTPDoer) *deepseek.Client {
+	t.Helper()
+	client, err := deepseek.NewClientWithOptions(apiKey, deepseek.WithHTTPClient(doer))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	return client
+}
@@ -27,14 +27,7 @@ func LoadTestConfig(t *testing.T) *TestConfig {
 
 	config := &TestConfig{
 		APIKey:      os.Getenv("TEST_DEEPSEEK_API_KEY"),
-		TestTimeout: 30 * time.Second,
-	}
-
-	// Override with environment variables if set
-	if timeout := os.Getenv("TEST_TIMEOUT"); timeout != "" {
-		if d, err := time.ParseDuration(timeout); err == nil {
-			config.TestTimeout = d
-		}
+		TestTimeout: defaultTestTimeout(),
 	}
 
 	// Skip tests if API key is not set
@@ -45,6 +38,16 @@ func LoadTestConfig(t *testing.T) *TestConfig {
 	return config
 }
 
+// defaultTestTimeout returns TEST_TIMEOUT if set and valid, 30 seconds otherwise.
+func defaultTestTimeout() time.Duration {
+	if timeout := os.Getenv("TEST_TIMEOUT"); timeout != "" {
+		if d, err := time.ParseDuration(timeout); err == nil {
+			return d
+		}
+	}
+	return 30 * time.Second
+}
+
 // SkipIfShort skips long-running tests when -short flag is used
 func SkipIfShort(t *testing.T) {
 	if testing.Short() {
@@ -0,0 +1,258 @@
+package deepseek
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNoActiveKey is returned by KeyPool when all of its keys have been retired.
+var ErrNoActiveKey = errors.New("no active API key")
+
+// KeyProvider supplies the API key of each request, in place of Client.AuthToken.
+// Implementations must be safe for concurrent use.
+type KeyProvider interface {
+	// Key returns the API key for the next request.
+	Key(ctx context.Context) (string, error)
+	// Report records the HTTP status code of a response to a request sent with key.
+	Report(key string, statusCode int)
+}
+
+// WithKeyProvider makes the client take the API key of each request from provider,
+// e.g. a KeyPool. The key passed to NewClientWithOptions is then ignored.
+func WithKeyProvider(provider KeyProvider) Option {
+	return func(c *Client) error {
+		if provider == nil {
+			return fmt.Errorf("key provider cannot be nil")
+		}
+		c.KeyProvider = provider
+		return nil
+	}
+}
+
+// authorize sets the API key of req, taking a new one from the key provider if the client has one,
+// and adapts req to the provider. It returns the key, to report the response to the key provider.
+func (c *Client) authorize(req *http.Request) (string, error) {
+	if c.KeyProvider != nil {
+		key, err := c.KeyProvider.Key(req.Context())
+		if err != nil {
+			return "", fmt.Errorf("error getting API key: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+	// The key is read before the provider may move it to another header.
+	key := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if c.Provider != nil {
+		if err := c.Provider.PrepareRequest(req); err != nil {
+			return "", fmt.Errorf("error preparing request: %w", err)
+		}
+	}
+	return key, nil
+}
+
+// DefaultRetireStatusCodes are the HTTP status codes that retire a key when KeyPool.RetireStatusCodes is empty.
+var DefaultRetireStatusCodes = []int{
+	http.StatusUnauthorized,    // 401: the key is invalid or was revoked.
+	http.StatusPaymentRequired, // 402: the account behind the key ran out of balance.
+}
+
+// KeyStats is a snapshot of the usage of a key in a KeyPool.
+type KeyStats struct {
+	Key           string    // The key, masked to its last four characters.
+	Requests      int       // Responses received for requests sent with the key.
+	Failures      int       // Responses with a status code >= 400.
+	RateLimited   int       // Responses with status 429.
+	Retired       bool      // Whether the key is no longer used.
+	RetiredStatus int       // Status code that retired the key.
+	LastUsed      time.Time // Time of the last response.
+}
+
+// KeyPool is a KeyProvider that hands out several API keys in round-robin order and retires
+// keys that are rejected with one of RetireStatusCodes. The keys can be replaced at runtime
+// with SetKeys or Refresh, without rebuilding the client. KeyPool is safe for concurrent use.
+type KeyPool struct {
+	RetireStatusCodes []int // Status codes that retire a key. Defaults to DefaultRetireStatusCodes.
+
+	mu     sync.Mutex
+	keys   []*pooledKey // In rotation order.
+	byName map[string]*pooledKey
+	next   int
+	load   func() ([]string, error) // Source of Refresh, nil if the pool was built from a list.
+	now    func() time.Time
+}
+
+type pooledKey struct {
+	key   string
+	stats KeyStats
+}
+
+// NewKeyPool creates a KeyPool with the given keys. Empty and duplicate keys are ignored.
+func NewKeyPool(keys ...string) (*KeyPool, error) {
+	p := &KeyPool{now: time.Now}
+	if err := p.SetKeys(keys...); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// NewKeyPoolFromEnv creates a KeyPool from an environment variable holding keys separated by commas,
+// e.g. DEEPSEEK_API_KEYS="sk-1,sk-2". Refresh reads the variable again.
+func NewKeyPoolFromEnv(name string) (*KeyPool, error) {
+	return newKeyPool(func() ([]string, error) {
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return nil, fmt.Errorf("environment variable %s is not set", name)
+		}
+		return strings.Split(value, ","), nil
+	})
+}
+
+// NewKeyPoolFromFile creates a KeyPool from a file with one key per line.
+// Blank lines and lines starting with "#" are ignored. Refresh reads the file again.
+func NewKeyPoolFromFile(path string) (*KeyPool, error) {
+	return newKeyPool(func() ([]string, error) {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("error opening key file: %w", err)
+		}
+		defer file.Close()
+		var keys []string
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			if line := strings.TrimSpace(scanner.Text()); !strings.HasPrefix(line, "#") {
+				keys = append(keys, line)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("error reading key file: %w", err)
+		}
+		return keys, nil
+	})
+}
+
+func newKeyPool(load func() ([]string, error)) (*KeyPool, error) {
+	p := &KeyPool{now: time.Now, load: load}
+	if err := p.Refresh(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Refresh reloads the keys from the environment variable or file the pool was created from.
+// It is a no-op for pools created with NewKeyPool.
+func (p *KeyPool) Refresh() error {
+	if p.load == nil {
+		return nil
+	}
+	keys, err := p.load()
+	if err != nil {
+		return err
+	}
+	return p.SetKeys(keys...)
+}
+
+// SetKeys replaces the keys of the pool. Keys that were already in the pool keep their statistics,
+// including their retirement; new keys start out active.
+func (p *KeyPool) SetKeys(keys ...string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	byName := make(map[string]*pooledKey, len(keys))
+	var pooled []*pooledKey
+	for _, key := range keys {
+		key = strings.TrimSpace(key)
+		if key == "" || byName[key] != nil {
+			continue
+		}
+		k := p.byName[key]
+		if k == nil {
+			k = &pooledKey{key: key, stats: KeyStats{Key: maskKey(key)}}
+		}
+		byName[key] = k
+		pooled = append(pooled, k)
+	}
+	if len(pooled) == 0 {
+		return fmt.Errorf("at least one API key is required")
+	}
+	p.keys = pooled
+	p.byName = byName
+	p.next = 0
+	return nil
+}
+
+// Key implements KeyProvider. It returns the next active key in round-robin order.
+func (p *KeyPool) Key(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i := 0; i < len(p.keys); i++ {
+		k := p.keys[(p.next+i)%len(p.keys)]
+		if !k.stats.Retired {
+			p.next = (p.next + i + 1) % len(p.keys)
+			return k.key, nil
+		}
+	}
+	return "", ErrNoActiveKey
+}
+
+// Report implements KeyProvider. It updates the statistics of key and retires it if
+// the status code is one of RetireStatusCodes.
+func (p *KeyPool) Report(key string, statusCode int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	k := p.byName[key]
+	if k == nil {
+		return // The key was removed by SetKeys while the request was in flight.
+	}
+	k.stats.Requests++
+	k.stats.LastUsed = p.now()
+	if statusCode < http.StatusBadRequest {
+		return
+	}
+	k.stats.Failures++
+	if statusCode == http.StatusTooManyRequests {
+		k.stats.RateLimited++
+	}
+	retire := p.RetireStatusCodes
+	if len(retire) == 0 {
+		retire = DefaultRetireStatusCodes
+	}
+	if !k.stats.Retired && slices.Contains(retire, statusCode) {
+		k.stats.Retired = true
+		k.stats.RetiredStatus = statusCode
+	}
+}
+
+// Restore puts a retired key back into rotation, e.g. after its account was topped up.
+func (p *KeyPool) Restore(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if k := p.byName[key]; k != nil {
+		k.stats.Retired = false
+		k.stats.RetiredStatus = 0
+	}
+}
+
+// Stats returns the statistics of the keys, in rotation order.
+func (p *KeyPool) Stats() []KeyStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	stats := make([]KeyStats, len(p.keys))
+	for i, k := range p.keys {
+		stats[i] = k.stats
+	}
+	return stats
+}
+
+// maskKey hides all but the last four characters of a key, so that statistics can be logged.
+func maskKey(key string) string {
+	if len(key) <= 4 {
+		return strings.Repeat("*", len(key))
+	}
+	return "..." + key[len(key)-4:]
+}
@@ -0,0 +1,159 @@
+package deepseek_test
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cohesion-org/deepseek-go"
+	"github.com/cohesion-org/deepseek-go/deepseektest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyPool_RoundRobin(t *testing.T) {
+	pool, err := deepseek.NewKeyPool("sk-key-one", "sk-key-two", "sk-key-one", " ")
+	require.NoError(t, err)
+	srv, client := newTestServer(t, deepseek.WithKeyProvider(pool))
+
+	for i := 0; i < 4; i++ {
+		_, err := client.CreateChatCompletion(context.Background(), testChatRequest())
+		require.NoError(t, err)
+	}
+	var keys []string
+	for _, r := range srv.Requests() {
+		keys = append(keys, r.Header.Get("Authorization"))
+	}
+	assert.Equal(t, []string{"Bearer sk-key-one", "Bearer sk-key-two", "Bearer sk-key-one", "Bearer sk-key-two"}, keys)
+
+	stats := pool.Stats()
+	require.Len(t, stats, 2, "duplicate and blank keys are ignored")
+	assert.Equal(t, "...-one", stats[0].Key)
+	assert.Equal(t, 2, stats[0].Requests)
+	assert.Equal(t, 2, stats[1].Requests)
+}
+
+func TestKeyPool_RetiresRejectedKeys(t *testing.T) {
+	pool, err := deepseek.NewKeyPool("revoked", "empty", "good")
+	require.NoError(t, err)
+	srv, client := newTestServer(t, deepseek.WithKeyProvider(pool))
+	srv.Enqueue(deepseektest.RouteChat,
+		deepseektest.Response{Status: http.StatusUnauthorized, Error: &deepseektest.ErrorBody{Message: "invalid key"}},
+		deepseektest.Response{Status: http.StatusPaymentRequired, Error: &deepseektest.ErrorBody{Message: "Insufficient Balance"}},
+		deepseektest.Response{Status: http.StatusTooManyRequests, Error: &deepseektest.ErrorBody{Message: "slow down"}},
+	)
+
+	for i := 0; i < 3; i++ {
+		_, err := client.CreateChatCompletion(context.Background(), testChatRequest())
+		require.Error(t, err)
+	}
+	_, err = client.CreateChatCompletion(context.Background(), testChatRequest())
+	require.NoError(t, err)
+
+	stats := pool.Stats()
+	assert.True(t, stats[0].Retired)
+	assert.Equal(t, http.StatusUnauthorized, stats[0].RetiredStatus)
+	assert.True(t, stats[1].Retired)
+	assert.Equal(t, http.StatusPaymentRequired, stats[1].RetiredStatus)
+	assert.False(t, stats[2].Retired, "rate limiting does not retire a key")
+	assert.Equal(t, 1, stats[2].RateLimited)
+	assert.Equal(t, 2, stats[2].Requests)
+
+	recorded, ok := srv.LastRequest()
+	require.True(t, ok)
+	assert.Equal(t, "Bearer good", recorded.Header.Get("Authorization"))
+
+	pool.Report("good", http.StatusUnauthorized)
+	_, err = client.CreateChatCompletion(context.Background(), testChatRequest())
+	assert.ErrorIs(t, err, deepseek.ErrNoActiveKey)
+
+	pool.Restore("empty")
+	_, err = client.CreateChatCompletion(context.Background(), testChatRequest())
+	require.NoError(t, err)
+}
+
+func TestKeyPool_RetryTakesNextKey(t *testing.T) {
+	pool, err := deepseek.NewKeyPool("key-a", "key-b")
+	require.NoError(t, err)
+	srv, client := newTestServer(t, deepseek.WithKeyProvider(pool), deepseek.WithRetryPolicy(fastRetryPolicy(2)))
+	srv.Enqueue(deepseektest.RouteChat, deepseektest.Response{Status: http.StatusTooManyRequests, Error: &deepseektest.ErrorBody{Message: "slow down"}})
+
+	_, err = client.CreateChatCompletion(context.Background(), testChatRequest())
+	require.NoError(t, err)
+
+	requests := srv.Requests()
+	require.Len(t, requests, 2)
+	assert.Equal(t, "Bearer key-a", requests[0].Header.Get("Authorization"))
+	assert.Equal(t, "Bearer key-b", requests[1].Header.Get("Authorization"), "the retry does not reuse the rate-limited key")
+	stats := pool.Stats()
+	assert.Equal(t, 1, stats[0].RateLimited)
+	assert.Equal(t, 1, stats[1].Requests)
+}
+
+func TestKeyPool_FromEnvAndRefresh(t *testing.T) {
+	t.Setenv("TEST_DEEPSEEK_KEYS", "sk-a, sk-b")
+	pool, err := deepseek.NewKeyPoolFromEnv("TEST_DEEPSEEK_KEYS")
+	require.NoError(t, err)
+	pool.Report("sk-a", http.StatusUnauthorized)
+
+	t.Setenv("TEST_DEEPSEEK_KEYS", "sk-a,sk-c")
+	require.NoError(t, pool.Refresh())
+	stats := pool.Stats()
+	require.Len(t, stats, 2)
+	assert.True(t, stats[0].Retired, "kept keys keep their state")
+	assert.Equal(t, "****", stats[0].Key)
+
+	key, err := pool.Key(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "sk-c", key)
+
+	_, err = deepseek.NewKeyPoolFromEnv("TEST_DEEPSEEK_KEYS_UNSET")
+	assert.Error(t, err)
+	t.Setenv("TEST_DEEPSEEK_KEYS", "")
+	assert.Error(t, pool.Refresh(), "a refresh without keys fails")
+	assert.Len(t, pool.Stats(), 2, "and keeps the previous keys")
+}
+
+func TestKeyPool_FromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys")
+	require.NoError(t, os.WriteFile(path, []byte("# team keys\nsk-one\n\nsk-two\n"), 0o600))
+	pool, err := deepseek.NewKeyPoolFromFile(path)
+	require.NoError(t, err)
+	assert.Len(t, pool.Stats(), 2)
+
+	require.NoError(t, os.WriteFile(path, []byte("sk-three\n"), 0o600))
+	require.NoError(t, pool.Refresh())
+	key, err := pool.Key(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "sk-three", key)
+
+	_, err = deepseek.NewKeyPoolFromFile(filepath.Join(t.TempDir(), "missing"))
+	assert.Error(t, err)
+}
+
+func TestKeyPool_WithProviderHeader(t *testing.T) {
+	pool, err := deepseek.NewKeyPool("azure-one", "azure-two")
+	require.NoError(t, err)
+	provider := deepseek.AzureProvider("https://example.openai.azure.com/", "")
+	srv, client := newTestServer(t, deepseek.WithProvider(provider), deepseek.WithKeyProvider(pool))
+	srv.Enqueue(deepseektest.RouteChat, deepseektest.Response{Status: http.StatusUnauthorized})
+
+	_, err = client.CreateChatCompletion(context.Background(), testChatRequest())
+	require.Error(t, err)
+	assert.True(t, pool.Stats()[0].Retired, "keys moved to another header are reported too")
+
+	_, err = client.CreateChatCompletion(context.Background(), testChatRequest())
+	require.NoError(t, err)
+	recorded, ok := srv.LastRequest()
+	require.True(t, ok)
+	assert.Equal(t, "azure-two", recorded.Header.Get("api-key"))
+}
+
+func TestWithKeyProvider_Nil(t *testing.T) {
+	_, err := deepseek.NewClientWithOptions("", deepseek.WithKeyProvider(nil))
+	assert.Error(t, err)
+	_, err = deepseek.NewKeyPool()
+	assert.Error(t, err)
+}
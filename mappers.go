@@ -2,6 +2,7 @@ package deepseek
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/cohesion-org/deepseek-go/constants"
 )
@@ -10,23 +11,27 @@ var validRoles = map[string]bool{
 	constants.ChatMessageRoleUser:      true,
 	constants.ChatMessageRoleAssistant: true,
 	constants.ChatMessageRoleSystem:    true,
+	constants.ChatMessageRoleTool:      true,
 }
 
-// MapMessageToChatCompletionMessage maps a Message to a ChatCompletionMessage
+// MapMessageToChatCompletionMessage maps a Message to a ChatCompletionMessage, so that a reply can be
+// appended to the history of the next request. Tool calls are kept; the content may only be empty if
+// the message has tool calls. The reasoning content is dropped, since the API rejects it in requests.
 func MapMessageToChatCompletionMessage(m Message) (ChatCompletionMessage, error) {
 	if m.Role == "" {
 		return ChatCompletionMessage{}, errors.New("message role cannot be empty")
 	}
 
-	if m.Content == "" {
+	if m.Content == "" && len(m.ToolCalls) == 0 {
 		return ChatCompletionMessage{}, errors.New("message content cannot be empty")
 	}
 	if !validRoles[m.Role] {
-		return ChatCompletionMessage{}, errors.New("invalid role: %s. Valid roles are can be found in official deepseek documentation")
+		return ChatCompletionMessage{}, fmt.Errorf("invalid role: %s. Valid roles are can be found in official deepseek documentation", m.Role)
 	}
 
 	return ChatCompletionMessage{
-		Role:    m.Role,
-		Content: m.Content,
+		Role:      m.Role,
+		Content:   m.Content,
+		ToolCalls: m.ToolCalls,
 	}, nil
 }
@@ -0,0 +1,71 @@
+package deepseek
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Operation identifies the API call a request was built for.
+type Operation string
+
+// Operations passed to middleware in Call.Operation.
+const (
+	OperationUnknown              Operation = ""                       // Raw requests sent through HandleSendChatCompletionRequest or HandleNormalRequest.
+	OperationChatCompletion       Operation = "chat_completion"        // CreateChatCompletion
+	OperationChatCompletionStream Operation = "chat_completion_stream" // CreateChatCompletionStream
+	OperationFIMCompletion        Operation = "fim_completion"         // CreateFIMCompletion
+	OperationFIMCompletionStream  Operation = "fim_completion_stream"  // CreateFIMStreamCompletion
+	OperationGetBalance           Operation = "get_balance"            // GetBalance
+	OperationListModels           Operation = "list_models"            // ListAllModels
+)
+
+// Call describes a single attempt of an API request as seen by middleware.
+type Call struct {
+	Operation   Operation     // The API call the request was built for.
+	Request     interface{}   // The typed request, e.g. *ChatCompletionRequest. Nil for GET endpoints and raw requests.
+	HTTPRequest *http.Request // The raw HTTP request that will be sent. Middleware may modify or replace it.
+}
+
+// Handler sends a Call and returns the raw HTTP response.
+type Handler func(call *Call) (*http.Response, error)
+
+// Middleware wraps a Handler to add cross-cutting behavior such as logging, header injection or metrics.
+//
+// Middleware runs once per attempt, inside the retry loop, right before the request reaches the HTTPDoer.
+// A middleware that does not call next must return either a response or an error.
+type Middleware func(next Handler) Handler
+
+// WithMiddleware registers middleware on the client. Middleware is applied in the order given:
+// the first one is the outermost and sees the call first.
+// Calling WithMiddleware several times appends to the chain.
+func WithMiddleware(middleware ...Middleware) Option {
+	return func(c *Client) error {
+		for _, mw := range middleware {
+			if mw == nil {
+				return fmt.Errorf("middleware cannot be nil")
+			}
+		}
+		c.Middleware = append(c.Middleware, middleware...)
+		return nil
+	}
+}
+
+// HeaderMiddleware returns a Middleware that sets the given headers on every request.
+func HeaderMiddleware(headers map[string]string) Middleware {
+	return func(next Handler) Handler {
+		return func(call *Call) (*http.Response, error) {
+			for key, value := range headers {
+				call.HTTPRequest.Header.Set(key, value)
+			}
+			return next(call)
+		}
+	}
+}
+
+// chain wraps the innermost handler with the middleware, the first middleware being the outermost.
+func chain(handler Handler, middleware []Middleware) Handler {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+	return handler
+}
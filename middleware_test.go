@@ -0,0 +1,142 @@
+package deepseek_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cohesion-org/deepseek-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubMiddleware answers every call with the given body without sending it.
+func stubMiddleware(body string) deepseek.Middleware {
+	return func(next deepseek.Handler) deepseek.Handler {
+		return func(call *deepseek.Call) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": []string{"application/json"}},
+				Body:       io.NopCloser(strings.NewReader(body)),
+				Request:    call.HTTPRequest,
+			}, nil
+		}
+	}
+}
+
+func TestMiddleware_OrderAndTypedRequest(t *testing.T) {
+	var gotHeader string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header.Get("X-Model")
+		w.Write([]byte(testChatResponse))
+	}))
+	defer ts.Close()
+
+	var order []string
+	record := func(name string) deepseek.Middleware {
+		return func(next deepseek.Handler) deepseek.Handler {
+			return func(call *deepseek.Call) (*http.Response, error) {
+				order = append(order, name)
+				return next(call)
+			}
+		}
+	}
+	modelHeader := func(next deepseek.Handler) deepseek.Handler {
+		return func(call *deepseek.Call) (*http.Response, error) {
+			assert.Equal(t, deepseek.OperationChatCompletion, call.Operation)
+			req, ok := call.Request.(*deepseek.ChatCompletionRequest)
+			require.True(t, ok, "expected *ChatCompletionRequest, got %T", call.Request)
+			call.HTTPRequest.Header.Set("X-Model", req.Model)
+			return next(call)
+		}
+	}
+
+	client, err := deepseek.NewClientWithOptions("token",
+		deepseek.WithBaseURL(ts.URL+"/"),
+		deepseek.WithMiddleware(record("first"), record("second")),
+		deepseek.WithMiddleware(modelHeader),
+	)
+	require.NoError(t, err)
+
+	_, err = client.CreateChatCompletion(context.Background(), testChatRequest())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"first", "second"}, order)
+	assert.Equal(t, deepseek.DeepSeekChat, gotHeader)
+}
+
+func TestMiddleware_RunsForEveryOperation(t *testing.T) {
+	var ops []deepseek.Operation
+	recordOp := func(next deepseek.Handler) deepseek.Handler {
+		return func(call *deepseek.Call) (*http.Response, error) {
+			ops = append(ops, call.Operation)
+			return next(call)
+		}
+	}
+
+	client, err := deepseek.NewClientWithOptions("token",
+		deepseek.WithMiddleware(recordOp, stubMiddleware(`{"object": "list", "data": [], "is_available": true}`)),
+	)
+	require.NoError(t, err)
+
+	_, err = deepseek.GetBalance(client, context.Background())
+	require.NoError(t, err)
+	_, err = deepseek.ListAllModels(client, context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, []deepseek.Operation{deepseek.OperationGetBalance, deepseek.OperationListModels}, ops)
+}
+
+func TestMiddleware_RunsOncePerAttempt(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(testChatResponse))
+	}))
+	defer ts.Close()
+
+	attempts := 0
+	client, err := deepseek.NewClientWithOptions("token",
+		deepseek.WithBaseURL(ts.URL+"/"),
+		deepseek.WithRetryPolicy(fastRetryPolicy(3)),
+		deepseek.WithMiddleware(func(next deepseek.Handler) deepseek.Handler {
+			return func(call *deepseek.Call) (*http.Response, error) {
+				attempts++
+				return next(call)
+			}
+		}),
+	)
+	require.NoError(t, err)
+
+	_, err = client.CreateChatCompletion(context.Background(), testChatRequest())
+	require.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+}
+
+func TestHeaderMiddleware(t *testing.T) {
+	var got string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("X-Title")
+		w.Write([]byte(testChatResponse))
+	}))
+	defer ts.Close()
+
+	client, err := deepseek.NewClientWithOptions("token",
+		deepseek.WithBaseURL(ts.URL+"/"),
+		deepseek.WithMiddleware(deepseek.HeaderMiddleware(map[string]string{"X-Title": "deepseek-go"})),
+	)
+	require.NoError(t, err)
+
+	_, err = client.CreateChatCompletion(context.Background(), testChatRequest())
+	require.NoError(t, err)
+	assert.Equal(t, "deepseek-go", got)
+
+	_, err = deepseek.NewClientWithOptions("token", deepseek.WithMiddleware(nil))
+	assert.Error(t, err)
+}
@@ -20,6 +20,7 @@ const (
 const (
 	AzureDeepSeekR1                     = "DeepSeek-R1"                            // Azure model for DeepSeek R1
 	OpenRouterDeepSeekR1                = "deepseek/deepseek-r1"                   // OpenRouter model for DeepSeek R1
+	OpenRouterDeepSeekV3                = "deepseek/deepseek-chat"                 // OpenRouter model for DeepSeek V3
 	OpenRouterDeepSeekR1DistillLlama70B = "deepseek/deepseek-r1-distill-llama-70b" // DeepSeek R1 Distill Llama 70B
 	OpenRouterDeepSeekR1DistillLlama8B  = "deepseek/deepseek-r1-distill-llama-8b"  // DeepSeek R1 Distill Llama 8B
 	OpenRouterDeepSeekR1DistillQwen14B  = "deepseek/deepseek-r1-distill-qwen-14b"  // DeepSeek R1 Distill Qwen 14B
@@ -43,7 +44,7 @@ type APIModels struct {
 // ListAllModels sends a request to the API to get all available models.
 func ListAllModels(c *Client, ctx context.Context) (*APIModels, error) {
 	req, err := utils.NewRequestBuilder(c.AuthToken).
-		SetBaseURL("https://api.deepseek.com/").
+		SetBaseURL(withTrailingSlash(c.BaseURL)).
 		SetPath("models").
 		BuildGet(ctx)
 
@@ -51,7 +52,7 @@ func ListAllModels(c *Client, ctx context.Context) (*APIModels, error) {
 		return nil, fmt.Errorf("error building request: %w", err)
 	}
 
-	resp, err := HandleNormalRequest(*c, req)
+	resp, err := c.handleCall(OperationListModels, nil, req)
 
 	if err != nil {
 		return nil, fmt.Errorf("error sending request: %w", err)
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/cohesion-org/deepseek-go"
+	"github.com/cohesion-org/deepseek-go/deepseektest"
 	"github.com/cohesion-org/deepseek-go/internal/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -44,3 +45,17 @@ func TestListAllModels(t *testing.T) {
 		}
 	}
 }
+
+func TestListAllModels_Offline(t *testing.T) {
+	srv, client := newTestServer(t)
+
+	resp, err := deepseek.ListAllModels(client, context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "list", resp.Object)
+	assert.NotEmpty(t, resp.Data)
+
+	last, ok := srv.LastRequest()
+	require.True(t, ok)
+	assert.Equal(t, deepseektest.RouteModels, last.Route)
+	assert.Equal(t, "GET", last.Method)
+}
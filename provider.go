@@ -0,0 +1,325 @@
+package deepseek
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// ErrUnsupportedOperation is returned for calls the client's provider does not serve, such as
+// GetBalance on OpenRouter. Such calls fail before anything is sent.
+var ErrUnsupportedOperation = errors.New("operation not supported by the provider")
+
+// Provider adapts the client to an API that is compatible with DeepSeek's but differs in its details,
+// such as Azure, OpenRouter, Ollama or vLLM. Select one with WithProvider.
+type Provider interface {
+	// PrepareRequest adapts an outgoing request, e.g. its authentication header, query or extra headers.
+	PrepareRequest(req *http.Request) error
+	// MapModel returns the provider's name for a model, or the name unchanged if it has no mapping.
+	MapModel(model string) string
+	// NormalizeResponse rewrites the JSON of a completion response or of a stream chunk into DeepSeek's format.
+	NormalizeResponse(data []byte) ([]byte, error)
+}
+
+// ProviderProfile is a Provider described by data, which covers most OpenAI-compatible APIs.
+// The functions AzureProvider, OpenRouterProvider, OllamaProvider and VLLMProvider return
+// profiles for common providers; their fields can be adjusted before use.
+type ProviderProfile struct {
+	Name    string // Name of the provider, for logs and errors.
+	BaseURL string // Base URL of the provider's API. WithProvider sets the client's BaseURL to it.
+	// BetaBaseURL is the base URL of the provider's beta endpoints, such as FIM completion.
+	// WithProvider sets the client's BetaBaseURL to it. If it is empty while BaseURL is set, the
+	// provider has no beta endpoints and FIM completions fail with ErrUnsupportedOperation.
+	BetaBaseURL string
+	// Unsupported lists the operations the provider does not serve, e.g. OperationGetBalance.
+	// They fail with ErrUnsupportedOperation instead of being sent to the provider.
+	Unsupported []Operation
+
+	// APIKeyHeader, if set, carries the API key instead of "Authorization: Bearer <key>", e.g. Azure's "api-key".
+	APIKeyHeader string
+	Headers      map[string]string // Headers added to every request, e.g. OpenRouter's "HTTP-Referer".
+	Query        map[string]string // Query parameters added to every request, e.g. Azure's "api-version".
+
+	// Models maps DeepSeek model names, such as DeepSeekReasoner, to the provider's names.
+	// Names without a mapping are sent unchanged.
+	Models map[string]string
+
+	// ReasoningField is the name of the field carrying the reasoning content in messages and deltas,
+	// if it is not "reasoning_content". It is renamed to "reasoning_content" in responses.
+	ReasoningField string
+	// ThinkTags moves a leading "<think>...</think>" block of a message's content into its reasoning
+	// content, for servers that inline the reasoning. Only non-streaming responses are rewritten.
+	ThinkTags bool
+}
+
+// PrepareRequest implements Provider.
+func (p *ProviderProfile) PrepareRequest(req *http.Request) error {
+	if p.APIKeyHeader != "" {
+		if key := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer "); key != "" {
+			req.Header.Del("Authorization")
+			req.Header.Set(p.APIKeyHeader, key)
+		}
+	}
+	for key, value := range p.Headers {
+		req.Header.Set(key, value)
+	}
+	if len(p.Query) > 0 {
+		query := req.URL.Query()
+		for key, value := range p.Query {
+			query.Set(key, value)
+		}
+		req.URL.RawQuery = query.Encode()
+	}
+	return nil
+}
+
+// MapModel implements Provider.
+func (p *ProviderProfile) MapModel(model string) string {
+	if mapped, ok := p.Models[model]; ok {
+		return mapped
+	}
+	return model
+}
+
+// NormalizeResponse implements Provider.
+func (p *ProviderProfile) NormalizeResponse(data []byte) ([]byte, error) {
+	renameReasoning := p.ReasoningField != "" && p.ReasoningField != "reasoning_content" &&
+		bytes.Contains(data, []byte(`"`+p.ReasoningField+`"`))
+	splitThink := p.ThinkTags && bytes.Contains(data, []byte("<think>"))
+	if !renameReasoning && !splitThink {
+		return data, nil
+	}
+
+	var response map[string]json.RawMessage
+	if err := json.Unmarshal(data, &response); err != nil {
+		return data, nil // Not a JSON object, e.g. an error page; leave it to the decoder.
+	}
+	var choices []map[string]json.RawMessage
+	if err := json.Unmarshal(response["choices"], &choices); err != nil {
+		return data, nil
+	}
+	for _, choice := range choices {
+		for _, key := range []string{"message", "delta"} {
+			var message map[string]json.RawMessage
+			if err := json.Unmarshal(choice[key], &message); err != nil || message == nil {
+				continue
+			}
+			if renameReasoning {
+				p.renameReasoning(message)
+			}
+			if splitThink && key == "message" {
+				splitThinkTags(message)
+			}
+			rewritten, err := json.Marshal(message)
+			if err != nil {
+				return nil, fmt.Errorf("error normalizing %s response: %w", p.Name, err)
+			}
+			choice[key] = rewritten
+		}
+	}
+	rewritten, err := json.Marshal(choices)
+	if err != nil {
+		return nil, fmt.Errorf("error normalizing %s response: %w", p.Name, err)
+	}
+	response["choices"] = rewritten
+	return json.Marshal(response)
+}
+
+func (p *ProviderProfile) renameReasoning(message map[string]json.RawMessage) {
+	reasoning, ok := message[p.ReasoningField]
+	if !ok {
+		return
+	}
+	delete(message, p.ReasoningField)
+	if existing, ok := message["reasoning_content"]; !ok || string(existing) == "null" {
+		message["reasoning_content"] = reasoning
+	}
+}
+
+func splitThinkTags(message map[string]json.RawMessage) {
+	var content string
+	if err := json.Unmarshal(message["content"], &content); err != nil {
+		return
+	}
+	trimmed := strings.TrimLeft(content, " \t\r\n")
+	if !strings.HasPrefix(trimmed, "<think>") {
+		return
+	}
+	end := strings.Index(trimmed, "</think>")
+	if end < 0 {
+		return
+	}
+	reasoning := strings.TrimSpace(trimmed[len("<think>"):end])
+	answer := strings.TrimLeft(trimmed[end+len("</think>"):], " \t\r\n")
+	message["content"], _ = json.Marshal(answer)
+	message["reasoning_content"], _ = json.Marshal(reasoning)
+}
+
+// AzureProvider returns the profile of DeepSeek models hosted on Azure AI. The endpoint defaults to
+// "https://models.inference.ai.azure.com/"; apiVersion, if set, is sent as the "api-version" query.
+func AzureProvider(endpoint, apiVersion string) *ProviderProfile {
+	if endpoint == "" {
+		endpoint = "https://models.inference.ai.azure.com/"
+	}
+	p := &ProviderProfile{
+		Name:         "azure",
+		BaseURL:      endpoint,
+		APIKeyHeader: "api-key",
+		Models:       map[string]string{DeepSeekReasoner: AzureDeepSeekR1},
+		Unsupported:  []Operation{OperationGetBalance, OperationListModels},
+	}
+	if apiVersion != "" {
+		p.Query = map[string]string{"api-version": apiVersion}
+	}
+	return p
+}
+
+// OpenRouterProvider returns the profile of OpenRouter. The optional referer and title identify
+// the application on openrouter.ai, through the "HTTP-Referer" and "X-Title" headers.
+func OpenRouterProvider(referer, title string) *ProviderProfile {
+	headers := map[string]string{}
+	if referer != "" {
+		headers["HTTP-Referer"] = referer
+	}
+	if title != "" {
+		headers["X-Title"] = title
+	}
+	return &ProviderProfile{
+		Name:        "openrouter",
+		BaseURL:     "https://openrouter.ai/api/v1/",
+		BetaBaseURL: "https://openrouter.ai/api/v1/",
+		Unsupported: []Operation{OperationGetBalance},
+		Headers:     headers,
+		Models: map[string]string{
+			DeepSeekChat:     OpenRouterDeepSeekV3,
+			DeepSeekReasoner: OpenRouterDeepSeekR1,
+		},
+		ReasoningField: "reasoning",
+	}
+}
+
+// OllamaProvider returns the profile of an Ollama server, through its OpenAI-compatible API.
+// The base URL defaults to "http://localhost:11434/v1/".
+func OllamaProvider(baseURL string) *ProviderProfile {
+	if baseURL == "" {
+		baseURL = "http://localhost:11434/v1/"
+	}
+	return &ProviderProfile{
+		Name:        "ollama",
+		BaseURL:     baseURL,
+		BetaBaseURL: baseURL,
+		Unsupported: []Operation{OperationGetBalance},
+		Models: map[string]string{
+			DeepSeekChat:     "deepseek-v3",
+			DeepSeekReasoner: "deepseek-r1",
+		},
+		ThinkTags: true,
+	}
+}
+
+// VLLMProvider returns the profile of a vLLM server serving the DeepSeek weights from Hugging Face.
+// The base URL defaults to "http://localhost:8000/v1/".
+func VLLMProvider(baseURL string) *ProviderProfile {
+	if baseURL == "" {
+		baseURL = "http://localhost:8000/v1/"
+	}
+	return &ProviderProfile{
+		Name:        "vllm",
+		BaseURL:     baseURL,
+		BetaBaseURL: baseURL,
+		Unsupported: []Operation{OperationGetBalance},
+		Models: map[string]string{
+			DeepSeekChat:     "deepseek-ai/DeepSeek-V3",
+			DeepSeekReasoner: "deepseek-ai/DeepSeek-R1",
+		},
+		ReasoningField: "reasoning",
+	}
+}
+
+// WithProvider selects the provider the client talks to. If the provider is a *ProviderProfile with
+// a BaseURL, the client's BaseURL and BetaBaseURL are set to the profile's; apply WithBaseURL or
+// WithBetaBaseURL afterwards to use other ones.
+func WithProvider(provider Provider) Option {
+	return func(c *Client) error {
+		if provider == nil {
+			return fmt.Errorf("provider cannot be nil")
+		}
+		c.Provider = provider
+		if profile, ok := provider.(*ProviderProfile); ok && profile.BaseURL != "" {
+			c.BaseURL = profile.BaseURL
+			c.BetaBaseURL = profile.BetaBaseURL
+		}
+		return nil
+	}
+}
+
+// checkOperation returns ErrUnsupportedOperation if the client's provider does not serve op.
+func (c *Client) checkOperation(op Operation) error {
+	profile, ok := c.Provider.(*ProviderProfile)
+	if !ok {
+		return nil
+	}
+	beta := op == OperationFIMCompletion || op == OperationFIMCompletionStream
+	if slices.Contains(profile.Unsupported, op) || beta && profile.BaseURL != "" && c.BetaBaseURL == "" {
+		return fmt.Errorf("%w: %s on %s", ErrUnsupportedOperation, op, profile.Name)
+	}
+	return nil
+}
+
+// providerModel returns the provider's name for model.
+func (c *Client) providerModel(model string) string {
+	if c.Provider == nil {
+		return model
+	}
+	return c.Provider.MapModel(model)
+}
+
+// mapModel returns request, or a copy of it if the provider maps its model.
+func (c *Client) mapModel(request *ChatCompletionRequest) *ChatCompletionRequest {
+	return withProviderModel(c, request, func(r *ChatCompletionRequest) *string { return &r.Model })
+}
+
+// mapFIMModel is mapModel for FIM completion requests.
+func (c *Client) mapFIMModel(request *FIMCompletionRequest) *FIMCompletionRequest {
+	return withProviderModel(c, request, func(r *FIMCompletionRequest) *string { return &r.Model })
+}
+
+// mapFIMStreamModel is mapModel for streaming FIM completion requests.
+func (c *Client) mapFIMStreamModel(request *FIMStreamCompletionRequest) *FIMStreamCompletionRequest {
+	return withProviderModel(c, request, func(r *FIMStreamCompletionRequest) *string { return &r.Model })
+}
+
+// withProviderModel returns request, or a copy of it with the provider's name for the model
+// field returned by model. The request of the caller is never modified.
+func withProviderModel[R any](c *Client, request *R, model func(*R) *string) *R {
+	name := c.providerModel(*model(request))
+	if name == *model(request) {
+		return request
+	}
+	mapped := *request
+	*model(&mapped) = name
+	return &mapped
+}
+
+// normalizeResponse rewrites the body of a non-streaming response with the provider's NormalizeResponse.
+func (c *Client) normalizeResponse(resp *http.Response) error {
+	if c.Provider == nil {
+		return nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+	normalized, err := c.Provider.NormalizeResponse(body)
+	if err != nil {
+		return err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(normalized))
+	return nil
+}
@@ -0,0 +1,168 @@
+package deepseek_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cohesion-org/deepseek-go"
+	"github.com/cohesion-org/deepseek-go/deepseektest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newReasonerRequest() *deepseek.ChatCompletionRequest {
+	return &deepseek.ChatCompletionRequest{
+		Model:    deepseek.DeepSeekReasoner,
+		Messages: []deepseek.ChatCompletionMessage{{Role: deepseek.ChatMessageRoleUser, Content: "Hi"}},
+	}
+}
+
+func TestProvider_OpenRouter(t *testing.T) {
+	provider := deepseek.OpenRouterProvider("https://example.com", "Example")
+	srv, client := newTestServer(t, deepseek.WithProvider(provider))
+	srv.Enqueue(deepseektest.RouteChat,
+		deepseektest.Response{Body: json.RawMessage(`{
+			"id": "gen-1", "object": "chat.completion", "created": 1, "model": "deepseek/deepseek-r1",
+			"choices": [{"index": 0, "finish_reason": "stop",
+				"message": {"role": "assistant", "content": "Hello!", "reasoning": "Greet back."}}],
+			"usage": {"prompt_tokens": 1, "completion_tokens": 2, "total_tokens": 3}
+		}`)},
+		deepseektest.Response{
+			Content:      "Streamed",
+			RawStreamEnd: "data: {\"id\":\"gen-2\",\"choices\":[{\"index\":0,\"delta\":{\"reasoning\":\"Thought.\"}}]}\n\n",
+		},
+	)
+	assert.Equal(t, "https://openrouter.ai/api/v1/", client.BaseURL)
+
+	request := newReasonerRequest()
+	resp, err := client.CreateChatCompletion(context.Background(), request)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello!", resp.Choices[0].Message.Content)
+	assert.Equal(t, "Greet back.", resp.Choices[0].Message.ReasoningContent)
+	assert.Equal(t, deepseek.DeepSeekReasoner, request.Model, "the caller's request is not modified")
+
+	recorded, ok := srv.LastRequest()
+	require.True(t, ok)
+	sent, err := recorded.ChatRequest()
+	require.NoError(t, err)
+	assert.Equal(t, deepseek.OpenRouterDeepSeekR1, sent.Model)
+	assert.Equal(t, "https://example.com", recorded.Header.Get("HTTP-Referer"))
+	assert.Equal(t, "Example", recorded.Header.Get("X-Title"))
+	assert.Equal(t, "Bearer test-key", recorded.Header.Get("Authorization"))
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), newReasonerRequest())
+	require.NoError(t, err)
+	defer stream.Close()
+	streamed, err := deepseek.AccumulateStream(stream)
+	require.NoError(t, err)
+	assert.Equal(t, "Streamed", streamed.Choices[0].Message.Content)
+	assert.Equal(t, "Thought.", streamed.Choices[0].Message.ReasoningContent)
+}
+
+func TestProvider_Azure(t *testing.T) {
+	var got *http.Request
+	var body deepseek.ChatCompletionRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","created":1,"model":"DeepSeek-R1",
+			"choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"Hi"}}]}`))
+	}))
+	defer srv.Close()
+
+	client, err := deepseek.NewClientWithOptions("azure-key",
+		deepseek.WithProvider(deepseek.AzureProvider(srv.URL+"/models/", "2024-05-01-preview")))
+	require.NoError(t, err)
+	_, err = client.CreateChatCompletion(context.Background(), newReasonerRequest())
+	require.NoError(t, err)
+
+	require.NotNil(t, got)
+	assert.Equal(t, "/models/chat/completions", got.URL.Path)
+	assert.Equal(t, "2024-05-01-preview", got.URL.Query().Get("api-version"))
+	assert.Equal(t, "azure-key", got.Header.Get("api-key"))
+	assert.Empty(t, got.Header.Get("Authorization"))
+	assert.Equal(t, deepseek.AzureDeepSeekR1, body.Model)
+}
+
+func TestProvider_OllamaThinkTags(t *testing.T) {
+	srv, client := newTestServer(t, deepseek.WithProvider(deepseek.OllamaProvider("")))
+	srv.Enqueue(deepseektest.RouteChat, deepseektest.Response{Body: json.RawMessage(`{
+		"id": "1", "object": "chat.completion", "created": 1, "model": "deepseek-r1",
+		"choices": [{"index": 0, "finish_reason": "stop",
+			"message": {"role": "assistant", "content": "<think>\nShort question.\n</think>\n\nHello!"}}]
+	}`)})
+
+	resp, err := client.CreateChatCompletion(context.Background(), newReasonerRequest())
+	require.NoError(t, err)
+	assert.Equal(t, "Hello!", resp.Choices[0].Message.Content)
+	assert.Equal(t, "Short question.", resp.Choices[0].Message.ReasoningContent)
+
+	recorded, ok := srv.LastRequest()
+	require.True(t, ok)
+	sent, err := recorded.ChatRequest()
+	require.NoError(t, err)
+	assert.Equal(t, "deepseek-r1", sent.Model)
+}
+
+func TestProviderProfile_MapModel(t *testing.T) {
+	for _, provider := range []*deepseek.ProviderProfile{
+		deepseek.AzureProvider("", ""),
+		deepseek.OpenRouterProvider("", ""),
+		deepseek.OllamaProvider(""),
+		deepseek.VLLMProvider(""),
+	} {
+		assert.NotEqual(t, deepseek.DeepSeekReasoner, provider.MapModel(deepseek.DeepSeekReasoner), provider.Name)
+		assert.Equal(t, "custom-model", provider.MapModel("custom-model"), provider.Name)
+		assert.NotEmpty(t, provider.BaseURL, provider.Name)
+	}
+
+	_, err := deepseek.NewClientWithOptions("key", deepseek.WithProvider(nil))
+	assert.Error(t, err)
+}
+
+func TestProvider_Endpoints(t *testing.T) {
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/models") {
+			_, _ = w.Write([]byte(`{"object":"list","data":[{"id":"deepseek-r1","object":"model","owned_by":"library"}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"1","object":"text_completion","created":1,"model":"deepseek-v3",
+			"choices":[{"index":0,"text":"return a + b","finish_reason":"stop"}]}`))
+	}))
+	defer srv.Close()
+
+	client, err := deepseek.NewClientWithOptions("key", deepseek.WithProvider(deepseek.OllamaProvider(srv.URL+"/v1/")))
+	require.NoError(t, err)
+	fim := &deepseek.FIMCompletionRequest{Model: deepseek.DeepSeekChat, Prompt: "def add(a, b):"}
+	_, err = client.CreateFIMCompletion(context.Background(), fim)
+	require.NoError(t, err)
+	_, err = deepseek.ListAllModels(client, context.Background())
+	require.NoError(t, err)
+	_, err = deepseek.GetBalance(client, context.Background())
+	assert.ErrorIs(t, err, deepseek.ErrUnsupportedOperation)
+	assert.Equal(t, []string{"/v1/completions", "/v1/models"}, paths, "the balance request is not sent")
+
+	client, err = deepseek.NewClientWithOptions("key", deepseek.WithProvider(deepseek.AzureProvider(srv.URL, "")))
+	require.NoError(t, err)
+	_, err = client.CreateFIMCompletion(context.Background(), fim)
+	assert.ErrorIs(t, err, deepseek.ErrUnsupportedOperation, "Azure has no beta endpoints")
+	_, err = client.CreateFIMStreamCompletion(context.Background(), &deepseek.FIMStreamCompletionRequest{Model: deepseek.DeepSeekChat, Prompt: "x"})
+	assert.ErrorIs(t, err, deepseek.ErrUnsupportedOperation)
+	_, err = deepseek.ListAllModels(client, context.Background())
+	assert.ErrorIs(t, err, deepseek.ErrUnsupportedOperation)
+
+	client, err = deepseek.NewClientWithOptions("key",
+		deepseek.WithProvider(deepseek.AzureProvider(srv.URL, "")), deepseek.WithBetaBaseURL(srv.URL+"/fim/"))
+	require.NoError(t, err)
+	_, err = client.CreateFIMCompletion(context.Background(), fim)
+	require.NoError(t, err, "an explicit beta base URL enables FIM completions")
+	assert.Equal(t, "/fim/completions", paths[len(paths)-1])
+}
@@ -0,0 +1,155 @@
+package deepseek
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// RateLimiter throttles requests per second and estimated tokens per minute on the client side.
+// A single RateLimiter may be shared by several clients and is safe for concurrent use.
+//
+// Capacity is reserved up front, using EstimateTokensFromMessages for the token budget, and the
+// estimate is corrected with Reconcile once the real Usage of the response is known.
+// The zero value does not limit anything; use NewRateLimiter to set limits.
+type RateLimiter struct {
+	mu       sync.Mutex
+	requests *tokenBucket // nil if requests are not limited
+	tokens   *tokenBucket // nil if tokens are not limited
+	now      func() time.Time
+}
+
+// NewRateLimiter creates a RateLimiter. A value <= 0 disables the corresponding limit.
+func NewRateLimiter(requestsPerSecond float64, tokensPerMinute int) *RateLimiter {
+	l := &RateLimiter{now: time.Now}
+	start := l.now()
+	if requestsPerSecond > 0 {
+		l.requests = newTokenBucket(math.Max(1, math.Ceil(requestsPerSecond)), requestsPerSecond, start)
+	}
+	if tokensPerMinute > 0 {
+		l.tokens = newTokenBucket(float64(tokensPerMinute), float64(tokensPerMinute)/60, start)
+	}
+	return l
+}
+
+// WithRateLimiter throttles chat completion requests with the given limiter.
+func WithRateLimiter(limiter *RateLimiter) Option {
+	return func(c *Client) error {
+		if limiter == nil {
+			return fmt.Errorf("rate limiter cannot be nil")
+		}
+		c.RateLimiter = limiter
+		return nil
+	}
+}
+
+// Wait blocks until capacity for one request and the given number of tokens is available,
+// or until ctx is done. Requests asking for more tokens than the per-minute limit are
+// clamped to the limit so they can eventually proceed.
+func (l *RateLimiter) Wait(ctx context.Context, tokens int) error {
+	if l == nil {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	now := l.clock()
+	var delay time.Duration
+	if l.requests != nil {
+		delay = max(delay, l.requests.reserve(1, now))
+	}
+	reserved := l.reservedTokens(tokens)
+	if l.tokens != nil {
+		delay = max(delay, l.tokens.reserve(reserved, now))
+	}
+	l.mu.Unlock()
+
+	if err := sleepContext(ctx, delay); err != nil {
+		// Give the reservation back so other callers are not delayed by a request that never happened.
+		l.mu.Lock()
+		if l.requests != nil {
+			l.requests.refund(1)
+		}
+		if l.tokens != nil {
+			l.tokens.refund(reserved)
+		}
+		l.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Reconcile corrects the token budget after a response, replacing the estimated
+// token count passed to Wait with the actual number of tokens used.
+func (l *RateLimiter) Reconcile(estimated, actual int) {
+	if l == nil || l.tokens == nil || estimated == actual {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens.refill(l.clock())
+	l.tokens.refund(l.reservedTokens(estimated) - float64(actual))
+}
+
+// reservedTokens returns the tokens Wait reserves for a request estimated at tokens:
+// the estimate, clamped to the per-minute limit.
+func (l *RateLimiter) reservedTokens(tokens int) float64 {
+	if l.tokens == nil {
+		return float64(tokens)
+	}
+	return math.Min(float64(tokens), l.tokens.capacity)
+}
+
+// clock returns the current time, falling back to time.Now for a zero RateLimiter.
+func (l *RateLimiter) clock() time.Time {
+	if l.now == nil {
+		return time.Now()
+	}
+	return l.now()
+}
+
+// tokenBucket is a token bucket whose level may become negative to queue reservations.
+type tokenBucket struct {
+	capacity float64   // Maximum number of tokens held by the bucket.
+	rate     float64   // Tokens added per second.
+	level    float64   // Tokens currently available. Negative while callers are queued.
+	last     time.Time // Last time the bucket was refilled.
+}
+
+func newTokenBucket(capacity, rate float64, now time.Time) *tokenBucket {
+	return &tokenBucket{capacity: capacity, rate: rate, level: capacity, last: now}
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.level = math.Min(b.capacity, b.level+elapsed*b.rate)
+		b.last = now
+	}
+}
+
+// reserve takes n tokens and returns how long the caller has to wait before using them.
+func (b *tokenBucket) reserve(n float64, now time.Time) time.Duration {
+	b.refill(now)
+	b.level -= n
+	if b.level >= 0 {
+		return 0
+	}
+	return time.Duration(-b.level / b.rate * float64(time.Second))
+}
+
+func (b *tokenBucket) refund(n float64) {
+	b.level = math.Min(b.capacity, b.level+n)
+}
+
+// estimateRequestTokens estimates the tokens a chat completion request will consume.
+func estimateRequestTokens(request *ChatCompletionRequest) int {
+	tokens := EstimateTokensFromMessages(request).EstimatedTokens
+	if request.MaxTokens != nil && *request.MaxTokens > 0 {
+		tokens += *request.MaxTokens
+	}
+	return tokens
+}
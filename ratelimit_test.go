@@ -0,0 +1,165 @@
+package deepseek_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cohesion-org/deepseek-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter_RequestsPerSecond(t *testing.T) {
+	limiter := deepseek.NewRateLimiter(10, 0)
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 12; i++ {
+		require.NoError(t, limiter.Wait(ctx, 0))
+	}
+	// The first 10 requests use the burst, the next 2 wait 100ms each.
+	assert.GreaterOrEqual(t, time.Since(start), 150*time.Millisecond)
+}
+
+func TestRateLimiter_TokensPerMinute(t *testing.T) {
+	limiter := deepseek.NewRateLimiter(0, 600) // 10 tokens per second
+	ctx := context.Background()
+
+	start := time.Now()
+	require.NoError(t, limiter.Wait(ctx, 600))
+	assert.Less(t, time.Since(start), 50*time.Millisecond, "full budget should be available immediately")
+
+	require.NoError(t, limiter.Wait(ctx, 3))
+	assert.GreaterOrEqual(t, time.Since(start), 250*time.Millisecond)
+}
+
+func TestRateLimiter_Reconcile(t *testing.T) {
+	limiter := deepseek.NewRateLimiter(0, 600)
+	ctx := context.Background()
+
+	require.NoError(t, limiter.Wait(ctx, 600))
+	// The request actually used far fewer tokens than estimated.
+	limiter.Reconcile(600, 10)
+
+	start := time.Now()
+	require.NoError(t, limiter.Wait(ctx, 500))
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+}
+
+func TestRateLimiter_ReconcileClampedEstimate(t *testing.T) {
+	limiter := deepseek.NewRateLimiter(0, 600) // 10 tokens per second
+	ctx := context.Background()
+
+	// Only the 600 tokens of the limit are reserved for an estimate of 6000.
+	require.NoError(t, limiter.Wait(ctx, 6000))
+	limiter.Reconcile(6000, 10)
+
+	ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	err := limiter.Wait(ctx, 600)
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "the tokens actually used are not credited back")
+}
+
+func TestRateLimiter_ContextCancellation(t *testing.T) {
+	limiter := deepseek.NewRateLimiter(0, 60) // 1 token per second
+	require.NoError(t, limiter.Wait(context.Background(), 60))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := limiter.Wait(ctx, 30)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestRateLimiter_NilIsUnlimited(t *testing.T) {
+	var limiter *deepseek.RateLimiter
+	assert.NoError(t, limiter.Wait(context.Background(), 1_000_000))
+	limiter.Reconcile(1, 2)
+
+	zero := &deepseek.RateLimiter{}
+	assert.NoError(t, zero.Wait(context.Background(), 1_000_000))
+	zero.Reconcile(1, 2)
+}
+
+func TestCreateChatCompletion_RateLimited(t *testing.T) {
+	var calls atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Write([]byte(testChatResponse))
+	}))
+	defer ts.Close()
+
+	limiter := deepseek.NewRateLimiter(0, 60)
+	client, err := deepseek.NewClientWithOptions("token",
+		deepseek.WithBaseURL(ts.URL+"/"),
+		deepseek.WithRateLimiter(limiter),
+	)
+	require.NoError(t, err)
+
+	// Exhaust the token budget so the next request has to wait.
+	require.NoError(t, limiter.Wait(context.Background(), 60))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = client.CreateChatCompletion(ctx, testChatRequest())
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, int32(0), calls.Load(), "request should not be sent while throttled")
+
+	_, err = deepseek.NewClientWithOptions("token", deepseek.WithRateLimiter(nil))
+	assert.Error(t, err)
+}
+
+func TestCreateChatCompletion_RateLimiterRefundedOnError(t *testing.T) {
+	invalid := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("not json"))
+	}))
+	defer invalid.Close()
+	closed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, _, err := w.(http.Hijacker).Hijack()
+		require.NoError(t, err)
+		conn.Close()
+	}))
+	defer closed.Close()
+
+	tests := []struct {
+		name string
+		url  string
+		call func(client *deepseek.Client, request *deepseek.ChatCompletionRequest) error
+	}{
+		{"decode error", invalid.URL, func(client *deepseek.Client, request *deepseek.ChatCompletionRequest) error {
+			_, err := client.CreateChatCompletion(context.Background(), request)
+			return err
+		}},
+		{"transport error", closed.URL, func(client *deepseek.Client, request *deepseek.ChatCompletionRequest) error {
+			_, err := client.CreateChatCompletion(context.Background(), request)
+			return err
+		}},
+		{"stream transport error", closed.URL, func(client *deepseek.Client, request *deepseek.ChatCompletionRequest) error {
+			_, err := client.CreateChatCompletionStream(context.Background(), request)
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := deepseek.NewRateLimiter(0, 600)
+			client, err := deepseek.NewClientWithOptions("token",
+				deepseek.WithBaseURL(tt.url+"/"),
+				deepseek.WithRateLimiter(limiter),
+			)
+			require.NoError(t, err)
+
+			request := testChatRequest()
+			maxTokens := 600 // Reserves the whole budget.
+			request.MaxTokens = &maxTokens
+			require.Error(t, tt.call(client, request))
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			assert.NoError(t, limiter.Wait(ctx, 600), "the reservation of the failed request should be refunded")
+		})
+	}
+}
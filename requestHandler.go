@@ -72,15 +72,51 @@ func HandleNormalRequest(c Client, req *http.Request) (*http.Response, error) {
 }
 
 func (c *Client) handleRequest(req *http.Request) (*http.Response, error) {
+	return c.handleCall(OperationUnknown, nil, req)
+}
+
+// handleCall sends req through the middleware chain, retrying it according to the retry policy.
+func (c *Client) handleCall(op Operation, request interface{}, req *http.Request) (*http.Response, error) {
+	if err := c.checkOperation(op); err != nil {
+		return nil, err
+	}
 	client := c.HTTPClient
 	if client == nil {
 		client = http.DefaultClient
 	}
+	handler := chain(func(call *Call) (*http.Response, error) {
+		return client.Do(call.HTTPRequest)
+	}, c.Middleware)
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("error sending request: %w", err)
-	}
+	policy := c.RetryPolicy
+	attempts := policy.maxAttempts()
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 {
+			if err := rewindBody(req); err != nil {
+				return nil, err
+			}
+		}
+		// Every attempt takes a new key, so that a retry after a 429 does not reuse a rate-limited key.
+		key, err := c.authorize(req)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := handler(&Call{Operation: op, Request: request, HTTPRequest: req})
+		if resp != nil && c.KeyProvider != nil {
+			c.KeyProvider.Report(key, resp.StatusCode)
+		}
+		if attempt >= attempts || !policy.retryable(req, resp, err) {
+			if err != nil {
+				return nil, fmt.Errorf("error sending request: %w", err)
+			}
+			return resp, nil
+		}
 
-	return resp, nil
+		delay := policy.backoff(attempt, resp)
+		drainAndClose(resp)
+		if err := sleepContext(req.Context(), delay); err != nil {
+			return nil, fmt.Errorf("error sending request: %w", err)
+		}
+	}
 }
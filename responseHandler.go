@@ -21,12 +21,24 @@ type ChatCompletionResponse struct {
 
 // Choice represents a completion choice generated by the model.
 type Choice struct {
-	Index        int       `json:"index"`              // Index of the choice in the list of choices.
-	Message      Message   `json:"message"`            // The message generated by the model.
-	Logprobs     *Logprobs `json:"logprobs,omitempty"` // Log probabilities of the tokens, if available.
-	FinishReason string    `json:"finish_reason"`      // Reason why the completion finished.
+	Index        int          `json:"index"`              // Index of the choice in the list of choices.
+	Message      Message      `json:"message"`            // The message generated by the model.
+	Logprobs     *Logprobs    `json:"logprobs,omitempty"` // Log probabilities of the tokens, if available.
+	FinishReason FinishReason `json:"finish_reason"`      // Reason why the completion finished.
 }
 
+// FinishReason is the reason why the model stopped generating a choice.
+type FinishReason string
+
+// Finish reasons reported in Choice.FinishReason, StreamChoices.FinishReason and the FIM choices.
+const (
+	FinishReasonStop                       FinishReason = "stop"                         // The model finished naturally or hit a stop sequence.
+	FinishReasonLength                     FinishReason = "length"                       // The max_tokens or context length limit was reached.
+	FinishReasonContentFilter              FinishReason = "content_filter"               // The output was filtered.
+	FinishReasonToolCalls                  FinishReason = "tool_calls"                   // The model stopped to call tools.
+	FinishReasonInsufficientSystemResource FinishReason = "insufficient_system_resource" // The request was interrupted for lack of resources.
+)
+
 // ToolCallFunction represents a function call in the tool.
 type ToolCallFunction struct {
 	Name      *string `json:"name"`      // Name of the function (required)
@@ -97,7 +109,7 @@ func HandleChatCompletionResponse(resp *http.Response) (*ChatCompletionResponse,
 
 	var parsedResponse ChatCompletionResponse
 	if err := json.Unmarshal(body, &parsedResponse); err != nil {
-		return nil, handleAPIError(body)
+		return nil, handleAPIError(resp, body, err)
 	}
 
 	if err := validateChatCompletionResponse(&parsedResponse); err != nil {
@@ -115,22 +127,47 @@ func HandleFIMCompletionRequest(resp *http.Response) (*FIMCompletionResponse, er
 
 	var parsedResponse FIMCompletionResponse
 	if err := json.Unmarshal(body, &parsedResponse); err != nil {
-		return nil, handleAPIError(body)
+		return nil, handleAPIError(resp, body, err)
 	}
 	return &parsedResponse, nil
 }
 
-// handleAPIError handles API errors by parsing the response body.
-func handleAPIError(body []byte) error {
-	responseBody := string(body)
+// DecodeError is returned when a response that reported success cannot be decoded,
+// e.g. because a proxy or an external provider answered with HTML or truncated JSON.
+type DecodeError struct {
+	StatusCode   int    // HTTP status code of the response
+	Message      string // Human-readable description of the problem
+	ResponseBody string // Raw response body
+	Err          error  // Underlying JSON error, nil for empty and HTML bodies
+}
 
-	if len(responseBody) == 0 {
-		return fmt.Errorf("failed to parse response JSON: empty response body")
+// Error returns a string representation of the error.
+func (e *DecodeError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("failed to parse response JSON: %v. %s", e.Err, e.ResponseBody)
 	}
-	if strings.HasPrefix(responseBody, "<!DOCTYPE html>") {
-		return fmt.Errorf("unexpected HTML response (model may not exist). This is likely an issue with the how some external servers return html responses for error. Make sure you are calling the right path or models")
+	return "failed to parse response JSON: " + e.Message
+}
+
+// Unwrap returns the underlying JSON error.
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// handleAPIError describes a response body that could not be decoded as a *DecodeError.
+func handleAPIError(resp *http.Response, body []byte, err error) error {
+	decodeErr := &DecodeError{StatusCode: resp.StatusCode, ResponseBody: string(body), Err: err}
+	switch {
+	case len(body) == 0:
+		decodeErr.Message = "empty response body"
+		decodeErr.Err = nil
+	case strings.HasPrefix(decodeErr.ResponseBody, "<!DOCTYPE html>"):
+		decodeErr.Message = "unexpected HTML response (model may not exist). This is likely an issue with the how some external servers return html responses for error. Make sure you are calling the right path or models"
+		decodeErr.Err = nil
+	default:
+		decodeErr.Message = err.Error()
 	}
-	return fmt.Errorf("failed to parse response JSON: unexpected end of JSON input. %s", responseBody)
+	return decodeErr
 }
 
 func validateChatCompletionResponse(parsedResponse *ChatCompletionResponse) error {
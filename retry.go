@@ -0,0 +1,273 @@
+package deepseek
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// Default values used by RetryPolicy when a field is left at its zero value.
+const (
+	DefaultRetryMaxAttempts    = 3
+	DefaultRetryInitialBackoff = 500 * time.Millisecond
+	DefaultRetryMaxBackoff     = 30 * time.Second
+	DefaultRetryMultiplier     = 2.0
+	DefaultRetryJitter         = 0.2
+)
+
+// DefaultRetryableStatusCodes are the HTTP status codes retried when RetryPolicy.RetryableStatusCodes is empty.
+var DefaultRetryableStatusCodes = []int{
+	http.StatusRequestTimeout,      // 408
+	http.StatusTooManyRequests,     // 429
+	http.StatusInternalServerError, // 500
+	http.StatusBadGateway,          // 502
+	http.StatusServiceUnavailable,  // 503
+	http.StatusGatewayTimeout,      // 504
+}
+
+// RetryPolicy configures how Client retries failed requests.
+// Every request sent by the client (chat, stream, FIM, balance and models) goes through it.
+type RetryPolicy struct {
+	MaxAttempts          int           // Total number of attempts, including the first one. Defaults to 3.
+	InitialBackoff       time.Duration // Delay before the first retry. Defaults to 500ms.
+	MaxBackoff           time.Duration // Upper bound of a computed backoff. Defaults to 30s.
+	Multiplier           float64       // Growth factor of the backoff between attempts. Defaults to 2.
+	Jitter               float64       // Fraction (0 to 1) of each backoff that is randomized. Defaults to 0.2.
+	RetryableStatusCodes []int         // HTTP status codes that are retried. Defaults to DefaultRetryableStatusCodes.
+	IgnoreRetryAfter     bool          // If true, the Retry-After response header is not honored.
+
+	// RetryAfterSendErrors also retries POST requests after network errors that may happen once the
+	// request was sent, such as a connection reset or an unexpected EOF. The server may then have
+	// processed the request already, so a completion can be generated, and billed, twice. By default,
+	// only GET requests are retried after such errors, and POST requests only when the connection
+	// could not be established.
+	RetryAfterSendErrors bool
+
+	// ShouldRetry optionally overrides the built-in classification.
+	// Exactly one of resp and err is non-nil.
+	ShouldRetry func(resp *http.Response, err error) bool
+}
+
+// DefaultRetryPolicy returns a RetryPolicy with the default values filled in.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:          DefaultRetryMaxAttempts,
+		InitialBackoff:       DefaultRetryInitialBackoff,
+		MaxBackoff:           DefaultRetryMaxBackoff,
+		Multiplier:           DefaultRetryMultiplier,
+		Jitter:               DefaultRetryJitter,
+		RetryableStatusCodes: DefaultRetryableStatusCodes,
+	}
+}
+
+// WithRetryPolicy enables automatic retries with the given policy.
+// Zero-valued fields fall back to the defaults of DefaultRetryPolicy.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) error {
+		if policy.MaxAttempts < 0 {
+			return fmt.Errorf("max attempts must not be negative")
+		}
+		if policy.Jitter < 0 || policy.Jitter > 1 {
+			return fmt.Errorf("jitter must be between 0 and 1")
+		}
+		if policy.Multiplier != 0 && policy.Multiplier < 1 {
+			return fmt.Errorf("multiplier must be >= 1")
+		}
+		c.RetryPolicy = &policy
+		return nil
+	}
+}
+
+func (p *RetryPolicy) maxAttempts() int {
+	if p == nil {
+		return 1
+	}
+	if p.MaxAttempts == 0 {
+		return DefaultRetryMaxAttempts
+	}
+	return p.MaxAttempts
+}
+
+// retryable reports whether a request that produced resp or err should be attempted again.
+func (p *RetryPolicy) retryable(req *http.Request, resp *http.Response, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+	if !canRewindBody(req) {
+		return false
+	}
+	if p.ShouldRetry != nil {
+		return p.ShouldRetry(resp, err)
+	}
+	if err != nil {
+		return isRetryableTransportError(err, isIdempotent(req) || p.RetryAfterSendErrors)
+	}
+
+	codes := p.RetryableStatusCodes
+	if len(codes) == 0 {
+		codes = DefaultRetryableStatusCodes
+	}
+	for _, code := range codes {
+		if resp.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns how long to wait after the given (1-based) failed attempt.
+func (p *RetryPolicy) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil && !p.IgnoreRetryAfter {
+		if d, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			return d
+		}
+	}
+
+	initial := p.InitialBackoff
+	if initial <= 0 {
+		initial = DefaultRetryInitialBackoff
+	}
+	maxBackoff := p.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultRetryMaxBackoff
+	}
+	multiplier := p.Multiplier
+	if multiplier == 0 {
+		multiplier = DefaultRetryMultiplier
+	}
+	jitter := p.Jitter
+	if jitter == 0 {
+		jitter = DefaultRetryJitter
+	}
+
+	delay := float64(initial) * math.Pow(multiplier, float64(attempt-1))
+	if delay > float64(maxBackoff) {
+		delay = float64(maxBackoff)
+	}
+	// Keep (1-jitter) of the delay fixed and randomize the rest.
+	delay = delay*(1-jitter) + rand.Float64()*delay*jitter
+	return time.Duration(delay)
+}
+
+// parseRetryAfter parses a Retry-After header given either in seconds or as an HTTP date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		d := date.Sub(now)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+// isRetryableTransportError reports whether err is a transient network failure. Failures that may
+// happen after the request was sent, such as resets and EOFs, are only retryable if afterSend is true.
+func isRetryableTransportError(err error, afterSend bool) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if isConnectError(err) {
+		return true
+	}
+	if !afterSend {
+		return false
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr)
+}
+
+// isConnectError reports whether err is a transient failure to connect, which happens before any
+// part of the request is written.
+func isConnectError(err error) bool {
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return dnsErr.IsTemporary || dnsErr.IsTimeout
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// isIdempotent reports whether req can be sent again without side effects.
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// canRewindBody reports whether the request body can be sent again.
+func canRewindBody(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// rewindBody replaces the consumed request body with a fresh copy.
+// Requests built by utils.AuthedRequest carry a one-shot bytes.Reader, but
+// http.NewRequestWithContext records GetBody for it, so it can be replayed.
+func rewindBody(req *http.Request) error {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+	if req.GetBody == nil {
+		return fmt.Errorf("request body cannot be rewound")
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return fmt.Errorf("error rewinding request body: %w", err)
+	}
+	req.Body = body
+	return nil
+}
+
+// sleepContext waits for d or until ctx is done, whichever happens first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// drainAndClose discards the rest of the body so the connection can be reused.
+func drainAndClose(resp *http.Response) {
+	if resp == nil || resp.Body == nil {
+		return
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	_ = resp.Body.Close()
+}
@@ -0,0 +1,282 @@
+package deepseek_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cohesion-org/deepseek-go"
+	"github.com/cohesion-org/deepseek-go/constants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testChatResponse = `{
+	"id": "chat-123",
+	"object": "chat.completion",
+	"created": 1677858242,
+	"model": "deepseek-chat",
+	"choices": [{"index": 0, "message": {"role": "assistant", "content": "Hello!"}, "finish_reason": "stop"}],
+	"usage": {"prompt_tokens": 5, "completion_tokens": 2, "total_tokens": 7}
+}`
+
+func fastRetryPolicy(attempts int) deepseek.RetryPolicy {
+	return deepseek.RetryPolicy{
+		MaxAttempts:    attempts,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     5 * time.Millisecond,
+	}
+}
+
+func testChatRequest() *deepseek.ChatCompletionRequest {
+	return &deepseek.ChatCompletionRequest{
+		Model: deepseek.DeepSeekChat,
+		Messages: []deepseek.ChatCompletionMessage{
+			{Role: constants.ChatMessageRoleUser, Content: "Say hello!"},
+		},
+	}
+}
+
+func TestRetryPolicy_RetriesTransientStatus(t *testing.T) {
+	var calls atomic.Int32
+	var bodies []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(testChatResponse))
+	}))
+	defer ts.Close()
+
+	client, err := deepseek.NewClientWithOptions("token",
+		deepseek.WithBaseURL(ts.URL+"/"),
+		deepseek.WithRetryPolicy(fastRetryPolicy(3)),
+	)
+	require.NoError(t, err)
+
+	resp, err := client.CreateChatCompletion(context.Background(), testChatRequest())
+	require.NoError(t, err)
+	assert.Equal(t, "Hello!", resp.Choices[0].Message.Content)
+	assert.Equal(t, int32(3), calls.Load())
+
+	// The body must be rewound between attempts.
+	require.Len(t, bodies, 3)
+	assert.NotEmpty(t, bodies[0])
+	assert.Equal(t, bodies[0], bodies[1])
+	assert.Equal(t, bodies[0], bodies[2])
+}
+
+func TestRetryPolicy_GivesUpAfterMaxAttempts(t *testing.T) {
+	var calls atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer ts.Close()
+
+	client, err := deepseek.NewClientWithOptions("token",
+		deepseek.WithBaseURL(ts.URL+"/"),
+		deepseek.WithRetryPolicy(fastRetryPolicy(2)),
+	)
+	require.NoError(t, err)
+
+	_, err = client.CreateChatCompletion(context.Background(), testChatRequest())
+	require.Error(t, err)
+	apiErr, ok := err.(*deepseek.APIError)
+	require.True(t, ok, "expected APIError, got %T", err)
+	assert.Equal(t, http.StatusTooManyRequests, apiErr.StatusCode)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestRetryPolicy_DoesNotRetryClientErrors(t *testing.T) {
+	var calls atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer ts.Close()
+
+	client, err := deepseek.NewClientWithOptions("token",
+		deepseek.WithBaseURL(ts.URL+"/"),
+		deepseek.WithRetryPolicy(fastRetryPolicy(5)),
+	)
+	require.NoError(t, err)
+
+	_, err = client.CreateChatCompletion(context.Background(), testChatRequest())
+	require.Error(t, err)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestRetryPolicy_HonorsRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(testChatResponse))
+	}))
+	defer ts.Close()
+
+	client, err := deepseek.NewClientWithOptions("token",
+		deepseek.WithBaseURL(ts.URL+"/"),
+		deepseek.WithRetryPolicy(fastRetryPolicy(2)),
+	)
+	require.NoError(t, err)
+
+	start := time.Now()
+	_, err = client.CreateChatCompletion(context.Background(), testChatRequest())
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), time.Second)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestRetryPolicy_StopsOnContextCancellation(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "10")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	client, err := deepseek.NewClientWithOptions("token",
+		deepseek.WithBaseURL(ts.URL+"/"),
+		deepseek.WithRetryPolicy(fastRetryPolicy(3)),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = client.CreateChatCompletion(ctx, testChatRequest())
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestRetryPolicy_RetriesGetRequests(t *testing.T) {
+	var calls atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`{"is_available": true, "balance_infos": []}`))
+	}))
+	defer ts.Close()
+
+	client, err := deepseek.NewClientWithOptions("token", deepseek.WithRetryPolicy(fastRetryPolicy(3)))
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodGet, ts.URL, nil)
+	require.NoError(t, err)
+	resp, err := deepseek.HandleNormalRequest(*client, req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+// newCutOffServer returns a server that hangs up in the middle of the response headers for the
+// first cutOffs requests, after reading the whole request, and answers the others with body.
+func newCutOffServer(t *testing.T, cutOffs int32, body string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.ReadAll(r.Body)
+		if calls.Add(1) > cutOffs {
+			w.Write([]byte(body))
+			return
+		}
+		conn, buf, err := http.NewResponseController(w).Hijack()
+		require.NoError(t, err)
+		_, _ = buf.WriteString("HTTP/1.1 200 OK\r\nContent-Type: application/json\r\n")
+		_ = buf.Flush()
+		_ = conn.Close()
+	}))
+	t.Cleanup(ts.Close)
+	return ts, &calls
+}
+
+func TestRetryPolicy_DoesNotResendPostCutOffMidResponse(t *testing.T) {
+	ts, calls := newCutOffServer(t, 1, testChatResponse)
+	client, err := deepseek.NewClientWithOptions("token",
+		deepseek.WithBaseURL(ts.URL+"/"),
+		deepseek.WithRetryPolicy(fastRetryPolicy(3)),
+	)
+	require.NoError(t, err)
+
+	_, err = client.CreateChatCompletion(context.Background(), testChatRequest())
+	require.Error(t, err)
+	assert.Equal(t, int32(1), calls.Load(), "the server may have generated the completion already")
+
+	policy := fastRetryPolicy(3)
+	policy.RetryAfterSendErrors = true
+	client.RetryPolicy = &policy
+	calls.Store(0)
+	resp, err := client.CreateChatCompletion(context.Background(), testChatRequest())
+	require.NoError(t, err)
+	assert.Equal(t, "Hello!", resp.Choices[0].Message.Content)
+	assert.Equal(t, int32(2), calls.Load(), "retried when explicitly allowed")
+}
+
+func TestRetryPolicy_RetriesGetCutOffMidResponse(t *testing.T) {
+	ts, calls := newCutOffServer(t, 1, `{"is_available": true, "balance_infos": []}`)
+	client, err := deepseek.NewClientWithOptions("token", deepseek.WithRetryPolicy(fastRetryPolicy(3)))
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodGet, ts.URL, nil)
+	require.NoError(t, err)
+	resp, err := deepseek.HandleNormalRequest(*client, req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.GreaterOrEqual(t, calls.Load(), int32(2))
+}
+
+func TestRetryPolicy_RetriesPostWhenConnectionFails(t *testing.T) {
+	// Reserve a free port and stop listening on it, so that every attempt fails to connect.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	var attempts atomic.Int32
+	client, err := deepseek.NewClientWithOptions("token",
+		deepseek.WithBaseURL("http://"+addr+"/"),
+		deepseek.WithRetryPolicy(fastRetryPolicy(3)),
+		deepseek.WithMiddleware(func(next deepseek.Handler) deepseek.Handler {
+			return func(call *deepseek.Call) (*http.Response, error) {
+				attempts.Add(1)
+				return next(call)
+			}
+		}),
+	)
+	require.NoError(t, err)
+
+	_, err = client.CreateChatCompletion(context.Background(), testChatRequest())
+	require.Error(t, err)
+	assert.Equal(t, int32(3), attempts.Load(), "nothing was sent, so the request is retried")
+}
+
+func TestWithRetryPolicy_Validation(t *testing.T) {
+	_, err := deepseek.NewClientWithOptions("token", deepseek.WithRetryPolicy(deepseek.RetryPolicy{MaxAttempts: -1}))
+	assert.Error(t, err)
+
+	_, err = deepseek.NewClientWithOptions("token", deepseek.WithRetryPolicy(deepseek.RetryPolicy{Jitter: 2}))
+	assert.Error(t, err)
+
+	client, err := deepseek.NewClientWithOptions("token", deepseek.WithRetryPolicy(deepseek.DefaultRetryPolicy()))
+	require.NoError(t, err)
+	require.NotNil(t, client.RetryPolicy)
+	assert.Equal(t, deepseek.DefaultRetryMaxAttempts, client.RetryPolicy.MaxAttempts)
+}
@@ -0,0 +1,11 @@
+package deepseek
+
+// Stream is a stream of chunks of type T, implemented by both chat and FIM completion streams.
+//
+// Recv returns the next chunk, and io.EOF once the stream is complete. Close releases the
+// underlying connection and must be called when the stream is no longer needed, unless it is
+// consumed with StreamIter or StreamChan, which close it automatically.
+type Stream[T any] interface {
+	Recv() (T, error)
+	Close() error
+}
@@ -0,0 +1,167 @@
+package deepseek
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/cohesion-org/deepseek-go/constants"
+)
+
+// StreamAccumulator rebuilds a complete ChatCompletionResponse from streamed chunks, so that
+// streaming and non-streaming code paths can share the same post-processing.
+//
+// Content and reasoning content are concatenated per choice index, tool call fragments are
+// merged with a ToolCallMerger, log probabilities are appended, and the usage of the final chunk is kept.
+// A StreamAccumulator is not safe for concurrent use.
+type StreamAccumulator struct {
+	id      string
+	created int64
+	model   string
+	choices map[int]*accumulatedChoice
+	usage   *Usage
+	chunks  int
+}
+
+type accumulatedChoice struct {
+	role         string
+	content      strings.Builder
+	reasoning    strings.Builder
+	toolCalls    *ToolCallMerger
+	logprobs     *Logprobs
+	finishReason FinishReason
+}
+
+// NewStreamAccumulator creates an empty StreamAccumulator.
+func NewStreamAccumulator() *StreamAccumulator {
+	return &StreamAccumulator{choices: make(map[int]*accumulatedChoice)}
+}
+
+// Add merges a chunk into the accumulated response. Nil chunks are ignored.
+func (a *StreamAccumulator) Add(chunk *StreamChatCompletionResponse) {
+	if chunk == nil {
+		return
+	}
+	a.chunks++
+	if a.id == "" {
+		a.id = chunk.ID
+	}
+	if a.created == 0 {
+		a.created = chunk.Created
+	}
+	if a.model == "" {
+		a.model = chunk.Model
+	}
+	if chunk.Usage != nil {
+		usage := *chunk.Usage
+		a.usage = &usage
+	}
+
+	for _, sc := range chunk.Choices {
+		choice := a.choices[sc.Index]
+		if choice == nil {
+			choice = &accumulatedChoice{toolCalls: NewToolCallMerger()}
+			a.choices[sc.Index] = choice
+		}
+		delta := sc.Delta
+		if delta.Role != nil && *delta.Role != "" {
+			choice.role = *delta.Role
+		}
+		if delta.Content != nil {
+			choice.content.WriteString(*delta.Content)
+		}
+		if delta.ReasoningContent != nil {
+			choice.reasoning.WriteString(*delta.ReasoningContent)
+		}
+		choice.toolCalls.AddChoice(sc)
+		if sc.Logprobs != nil {
+			if choice.logprobs == nil {
+				choice.logprobs = &Logprobs{}
+			}
+			choice.logprobs.Content = append(choice.logprobs.Content, sc.Logprobs.Content...)
+		}
+		if sc.FinishReason != nil && *sc.FinishReason != "" {
+			choice.finishReason = *sc.FinishReason
+		}
+	}
+}
+
+// Usage returns the usage reported by the stream, or nil if none was received yet.
+func (a *StreamAccumulator) Usage() *Usage {
+	return a.usage
+}
+
+// Response returns the response accumulated so far. Choices are ordered by index.
+// It can be called at any time, including after a stream error, to get the partial response.
+//
+// Tool calls of choices that finished with "tool_calls" are validated; if one is invalid the
+// response is still returned, along with an error wrapping ErrInvalidToolCall.
+func (a *StreamAccumulator) Response() (*ChatCompletionResponse, error) {
+	if a.chunks == 0 {
+		return nil, errors.New("no chunks received")
+	}
+
+	resp := &ChatCompletionResponse{
+		ID:      a.id,
+		Object:  "chat.completion",
+		Created: a.created,
+		Model:   a.model,
+		Choices: make([]Choice, 0, len(a.choices)),
+	}
+	if a.usage != nil {
+		resp.Usage = *a.usage
+	}
+
+	indexes := make([]int, 0, len(a.choices))
+	for index := range a.choices {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+
+	var toolCallErr error
+	for _, index := range indexes {
+		choice := a.choices[index]
+		role := choice.role
+		if role == "" {
+			role = constants.ChatMessageRoleAssistant
+		}
+		toolCalls := choice.toolCalls.Partial()
+		if choice.toolCalls.Done() {
+			if _, err := choice.toolCalls.ToolCalls(); err != nil && toolCallErr == nil {
+				toolCallErr = fmt.Errorf("choice %d: %w", index, err)
+			}
+		}
+		resp.Choices = append(resp.Choices, Choice{
+			Index: index,
+			Message: Message{
+				Role:             role,
+				Content:          choice.content.String(),
+				ReasoningContent: choice.reasoning.String(),
+				ToolCalls:        toolCalls,
+			},
+			Logprobs:     choice.logprobs,
+			FinishReason: choice.finishReason,
+		})
+	}
+	return resp, toolCallErr
+}
+
+// AccumulateStream reads the stream until it ends and returns the complete response.
+// The stream is not closed. If reading fails, the partial response received so far is
+// returned along with the error.
+func AccumulateStream(stream ChatCompletionStream) (*ChatCompletionResponse, error) {
+	acc := NewStreamAccumulator()
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return acc.Response()
+		}
+		if err != nil {
+			partial, _ := acc.Response()
+			return partial, fmt.Errorf("error receiving stream: %w", err)
+		}
+		acc.Add(chunk)
+	}
+}
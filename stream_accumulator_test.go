@@ -0,0 +1,109 @@
+package deepseek_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cohesion-org/deepseek-go"
+	"github.com/cohesion-org/deepseek-go/deepseektest"
+	"github.com/cohesion-org/deepseek-go/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamAccumulator_MultipleChoices(t *testing.T) {
+	acc := deepseek.NewStreamAccumulator()
+	_, err := acc.Response()
+	require.Error(t, err, "empty accumulator should not produce a response")
+
+	acc.Add(&deepseek.StreamChatCompletionResponse{
+		ID: "chunk-1", Created: 42, Model: deepseek.DeepSeekReasoner,
+		Choices: []deepseek.StreamChoices{
+			{Index: 1, Delta: deepseek.StreamDelta{Role: utils.StringPtr("assistant"), Content: utils.StringPtr("Bon")}},
+			{Index: 0, Delta: deepseek.StreamDelta{ReasoningContent: utils.StringPtr("Think")}},
+		},
+	})
+	acc.Add(&deepseek.StreamChatCompletionResponse{
+		ID: "chunk-1",
+		Choices: []deepseek.StreamChoices{
+			{Index: 0, Delta: deepseek.StreamDelta{ReasoningContent: utils.StringPtr("ing"), Content: utils.StringPtr("Hello")},
+				Logprobs: &deepseek.Logprobs{Content: []deepseek.ContentToken{{Token: "Hello", Logprob: -0.1}}}},
+			{Index: 1, Delta: deepseek.StreamDelta{Content: utils.StringPtr("jour")}},
+		},
+	})
+	acc.Add(nil)
+	acc.Add(&deepseek.StreamChatCompletionResponse{
+		ID: "chunk-1",
+		Choices: []deepseek.StreamChoices{
+			{Index: 0, FinishReason: finishReasonPtr(deepseek.FinishReasonStop),
+				Logprobs: &deepseek.Logprobs{Content: []deepseek.ContentToken{{Token: "!", Logprob: -0.2}}}},
+			{Index: 1, FinishReason: finishReasonPtr(deepseek.FinishReasonLength)},
+		},
+		Usage: &deepseek.Usage{PromptTokens: 3, CompletionTokens: 4, TotalTokens: 7},
+	})
+
+	resp, err := acc.Response()
+	require.NoError(t, err)
+	assert.Equal(t, "chunk-1", resp.ID)
+	assert.Equal(t, "chat.completion", resp.Object)
+	assert.Equal(t, int64(42), resp.Created)
+	assert.Equal(t, deepseek.DeepSeekReasoner, resp.Model)
+	assert.Equal(t, 7, resp.Usage.TotalTokens)
+	require.NotNil(t, acc.Usage())
+
+	require.Len(t, resp.Choices, 2)
+	first, second := resp.Choices[0], resp.Choices[1]
+	assert.Equal(t, 0, first.Index)
+	assert.Equal(t, "assistant", first.Message.Role)
+	assert.Equal(t, "Hello", first.Message.Content)
+	assert.Equal(t, "Thinking", first.Message.ReasoningContent)
+	assert.Equal(t, deepseek.FinishReasonStop, first.FinishReason)
+	require.NotNil(t, first.Logprobs)
+	assert.Len(t, first.Logprobs.Content, 2)
+
+	assert.Equal(t, 1, second.Index)
+	assert.Equal(t, "Bonjour", second.Message.Content)
+	assert.Equal(t, deepseek.FinishReasonLength, second.FinishReason)
+	assert.Nil(t, second.Logprobs)
+}
+
+func TestAccumulateStream(t *testing.T) {
+	srv, client := newTestServer(t)
+	srv.Enqueue(deepseektest.RouteChat, deepseektest.Response{
+		Content:          "Here you go: ```json\n{\"name\": \"Go\", \"year\": 2009}\n```",
+		ReasoningContent: "The user wants JSON.",
+		ToolCalls: []deepseek.ToolCall{{
+			Function: deepseek.ToolCallFunction{Name: utils.StringPtr("lookup"), Arguments: `{"q":"golang"}`},
+		}},
+	})
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), testChatRequest())
+	require.NoError(t, err)
+	defer stream.Close()
+
+	resp, err := deepseek.AccumulateStream(stream)
+	require.NoError(t, err)
+	require.Len(t, resp.Choices, 1)
+
+	msg := resp.Choices[0].Message
+	assert.Equal(t, "The user wants JSON.", msg.ReasoningContent)
+	assert.Equal(t, deepseek.FinishReasonToolCalls, resp.Choices[0].FinishReason)
+	require.Len(t, msg.ToolCalls, 1)
+	assert.Equal(t, "call_0", *msg.ToolCalls[0].ID)
+	assert.Equal(t, "lookup", *msg.ToolCalls[0].Function.Name)
+	assert.Equal(t, `{"q":"golang"}`, msg.ToolCalls[0].Function.Arguments)
+	assert.NotZero(t, resp.Usage.TotalTokens)
+
+	// The accumulated response works with the same post-processing as a regular one.
+	var lang struct {
+		Name string `json:"name"`
+		Year int    `json:"year"`
+	}
+	require.NoError(t, deepseek.NewJSONExtractor(nil).ExtractJSON(resp, &lang))
+	assert.Equal(t, "Go", lang.Name)
+	assert.Equal(t, 2009, lang.Year)
+}
+
+func finishReasonPtr(reason deepseek.FinishReason) *deepseek.FinishReason {
+	return &reason
+}
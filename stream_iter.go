@@ -0,0 +1,87 @@
+package deepseek
+
+import (
+	"context"
+	"errors"
+	"io"
+	"iter"
+	"sync"
+)
+
+// StreamResult is a chunk or an error delivered by StreamChan.
+type StreamResult[T any] struct {
+	Chunk T     // The received chunk, the zero value if Err is set.
+	Err   error // The error that ended the stream. io.EOF is never delivered.
+}
+
+// StreamIter returns an iterator over the chunks of a chat or FIM completion stream, for use with range:
+//
+//	for chunk, err := range deepseek.StreamIter(stream) {
+//		if err != nil {
+//			return err
+//		}
+//		fmt.Print(*chunk.Choices[0].Delta.Content)
+//	}
+//
+// The iteration ends after the last chunk or after yielding an error, and the stream is
+// closed when the loop exits, including on break. The stream can only be iterated once.
+func StreamIter[T any](stream Stream[T]) iter.Seq2[T, error] {
+	return iterateStream(stream.Recv, stream.Close)
+}
+
+// StreamChan delivers the chunks of a chat or FIM completion stream on a channel, for select-driven
+// code. The channel is closed after the last chunk, or after a result carrying the error that ended
+// the stream. The stream is closed once the channel is closed.
+//
+// Cancelling ctx closes the stream and the channel without delivering further results, so a
+// consumer that stops reading early must cancel ctx to release the stream.
+func StreamChan[T any](ctx context.Context, stream Stream[T]) <-chan StreamResult[T] {
+	return streamChan(ctx, stream.Recv, stream.Close)
+}
+
+func iterateStream[T any](recv func() (T, error), closeStream func() error) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		defer func() { _ = closeStream() }()
+		for {
+			chunk, err := recv()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			if !yield(chunk, nil) {
+				return
+			}
+		}
+	}
+}
+
+func streamChan[T any](ctx context.Context, recv func() (T, error), closeStream func() error) <-chan StreamResult[T] {
+	ch := make(chan StreamResult[T])
+	var once sync.Once
+	closeOnce := func() error {
+		once.Do(func() { _ = closeStream() })
+		return nil
+	}
+	// Closing the stream unblocks a pending recv when ctx is cancelled.
+	stop := context.AfterFunc(ctx, func() { _ = closeOnce() })
+
+	go func() {
+		defer close(ch)
+		defer stop()
+		for chunk, err := range iterateStream(recv, closeOnce) {
+			if ctx.Err() != nil {
+				return
+			}
+			select {
+			case ch <- StreamResult[T]{Chunk: chunk, Err: err}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
@@ -0,0 +1,161 @@
+package deepseek_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cohesion-org/deepseek-go"
+	"github.com/cohesion-org/deepseek-go/deepseektest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStream(t *testing.T, responses ...deepseektest.Response) deepseek.ChatCompletionStream {
+	t.Helper()
+	srv, client := newTestServer(t)
+	srv.Enqueue(deepseektest.RouteChat, responses...)
+	stream, err := client.CreateChatCompletionStream(context.Background(), testChatRequest())
+	require.NoError(t, err)
+	return stream
+}
+
+func TestStreamIter(t *testing.T) {
+	stream := newTestStream(t, deepseektest.Response{Chunks: []string{"one ", "two ", "three"}})
+
+	var content string
+	var usage *deepseek.Usage
+	for chunk, err := range deepseek.StreamIter(stream) {
+		require.NoError(t, err)
+		if len(chunk.Choices) > 0 && chunk.Choices[0].Delta.Content != nil {
+			content += *chunk.Choices[0].Delta.Content
+		}
+		if chunk.Usage != nil {
+			usage = chunk.Usage
+		}
+	}
+	assert.Equal(t, "one two three", content)
+	assert.NotNil(t, usage)
+}
+
+func TestStreamIter_BreakClosesStream(t *testing.T) {
+	stream := newTestStream(t, deepseektest.Response{Chunks: []string{"one ", "two ", "three"}, ChunkDelay: 10 * time.Millisecond})
+
+	for _, err := range deepseek.StreamIter(stream) {
+		require.NoError(t, err)
+		break
+	}
+	_, err := stream.Recv()
+	assert.Error(t, err, "the stream should be closed after break")
+}
+
+func TestStreamIter_Error(t *testing.T) {
+	stream := newTestStream(t, deepseektest.Response{Content: "partial", OmitDone: true})
+
+	var errs []error
+	for _, err := range deepseek.StreamIter(stream) {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	require.Len(t, errs, 1, "the error is yielded once and ends the iteration")
+	assert.ErrorIs(t, errs[0], deepseek.ErrStreamTruncated)
+}
+
+func TestStreamChan(t *testing.T) {
+	stream := newTestStream(t, deepseektest.Response{Chunks: []string{"one ", "two"}, StreamError: &deepseektest.ErrorBody{Message: "overloaded"}})
+
+	var content string
+	var lastErr error
+	for result := range deepseek.StreamChan(context.Background(), stream) {
+		if result.Err != nil {
+			lastErr = result.Err
+			continue
+		}
+		if len(result.Chunk.Choices) > 0 && result.Chunk.Choices[0].Delta.Content != nil {
+			content += *result.Chunk.Choices[0].Delta.Content
+		}
+	}
+	assert.Equal(t, "one two", content)
+	var apiErr *deepseek.APIError
+	require.ErrorAs(t, lastErr, &apiErr)
+	assert.Equal(t, "overloaded", apiErr.Message)
+}
+
+func TestStreamChan_Cancel(t *testing.T) {
+	stream := newTestStream(t, deepseektest.Response{Chunks: []string{"one ", "two"}, ChunkDelay: 10 * time.Second})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	results := deepseek.StreamChan(ctx, stream)
+	first := <-results
+	require.NoError(t, first.Err)
+	cancel()
+
+	select {
+	case _, ok := <-results:
+		assert.False(t, ok, "no results are delivered after cancellation")
+	case <-time.After(5 * time.Second):
+		t.Fatal("the channel was not closed after cancellation")
+	}
+}
+
+func TestFIMStreamIter(t *testing.T) {
+	srv, client := newTestServer(t)
+	srv.Enqueue(deepseektest.RouteFIM, deepseektest.Response{Chunks: []string{"return ", "a + b"}})
+
+	request := &deepseek.FIMStreamCompletionRequest{Model: deepseek.DeepSeekChat, Prompt: "def add(a, b):"}
+	stream, err := client.CreateFIMStreamCompletion(context.Background(), request)
+	require.NoError(t, err)
+	var text string
+	for chunk, err := range deepseek.StreamIter(stream) {
+		require.NoError(t, err)
+		text += chunk.Choices[0].Text
+	}
+	assert.Equal(t, "return a + b", text)
+
+	stream, err = client.CreateFIMStreamCompletion(context.Background(), request)
+	require.NoError(t, err)
+	text = ""
+	for result := range deepseek.StreamChan(context.Background(), stream) {
+		require.NoError(t, result.Err)
+		text += result.Chunk.Choices[0].Text
+	}
+	assert.Equal(t, "return a + b", text, "the default response is served again")
+}
+
+// drain reads any stream to the end, which works for chat and FIM streams alike.
+func drain[T any](t *testing.T, stream deepseek.Stream[T]) []T {
+	t.Helper()
+	defer stream.Close()
+	var chunks []T
+	for chunk, err := range deepseek.StreamIter(stream) {
+		require.NoError(t, err)
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+func TestStream_ChatAndFIM(t *testing.T) {
+	srv, client := newTestServer(t)
+	srv.Enqueue(deepseektest.RouteChat, deepseektest.Response{Chunks: []string{"Hi ", "there"}})
+	srv.Enqueue(deepseektest.RouteFIM, deepseektest.Response{Chunks: []string{"return ", "a + b"}, FinishReason: deepseek.FinishReasonLength})
+
+	chat, err := client.CreateChatCompletionStream(context.Background(), testChatRequest())
+	require.NoError(t, err)
+	chatChunks := drain(t, chat)
+	last := chatChunks[len(chatChunks)-1]
+	require.NotNil(t, last.Choices[0].FinishReason)
+	assert.Equal(t, deepseek.FinishReasonStop, *last.Choices[0].FinishReason)
+
+	fim, err := client.CreateFIMStreamCompletion(context.Background(), &deepseek.FIMStreamCompletionRequest{
+		Model:  deepseek.DeepSeekChat,
+		Prompt: "def add(a, b):",
+	})
+	require.NoError(t, err)
+	fimChunks := drain(t, fim)
+	require.Len(t, fimChunks, 3)
+	assert.Nil(t, fimChunks[0].Choices[0].FinishReason, "only the last chunk has a finish reason")
+	require.NotNil(t, fimChunks[2].Choices[0].FinishReason)
+	assert.Equal(t, deepseek.FinishReasonLength, *fimChunks[2].Choices[0].FinishReason)
+	require.NotNil(t, fimChunks[2].Usage)
+}
@@ -0,0 +1,130 @@
+package deepseek
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/cohesion-org/deepseek-go/internal/sse"
+)
+
+// streamDone is the data of the event that terminates a stream.
+const streamDone = "[DONE]"
+
+// ErrStreamTruncated is returned by Recv when the connection ends before the server sent "[DONE]".
+// It wraps io.ErrUnexpectedEOF, so a cut-off stream can be told apart from a clean io.EOF.
+var ErrStreamTruncated = errors.New("stream ended before [DONE]")
+
+// ErrStreamIdleTimeout is returned by Recv when no data arrived for Client.StreamIdleTimeout.
+// Like a net.Error it reports Timeout() == true. Recv runs after the stream was created, so
+// RetryPolicy does not retry it: callers must close the stream and issue the request again.
+var ErrStreamIdleTimeout error = &streamIdleTimeoutError{}
+
+type streamIdleTimeoutError struct{}
+
+func (*streamIdleTimeoutError) Error() string   { return "stream idle timeout" }
+func (*streamIdleTimeoutError) Timeout() bool   { return true }
+func (*streamIdleTimeoutError) Temporary() bool { return true }
+
+// streamReader reads the server-sent events of a chat or FIM stream.
+type streamReader struct {
+	events     *sse.Decoder
+	statusCode int
+	err        error // Sticky error, returned again by later calls to next.
+
+	normalize func([]byte) ([]byte, error) // Rewrites the data of chunks, for providers (optional).
+
+	idleTimeout time.Duration // Longest wait for data while next is blocked, 0 for none.
+	idleTimer   *time.Timer   // Cancels the request when it fires.
+	timedOut    atomic.Bool
+}
+
+// newStreamReader returns a reader for the events of resp. If idleTimeout is positive,
+// cancel is called when next waits longer than idleTimeout for the next bytes.
+func newStreamReader(resp *http.Response, idleTimeout time.Duration, cancel context.CancelFunc) *streamReader {
+	r := &streamReader{statusCode: resp.StatusCode, idleTimeout: idleTimeout}
+	var body io.Reader = resp.Body
+	if idleTimeout > 0 {
+		r.idleTimer = time.AfterFunc(idleTimeout, func() {
+			r.timedOut.Store(true)
+			cancel()
+		})
+		r.idleTimer.Stop()
+		body = &activityReader{r: body, onData: func() { r.idleTimer.Reset(idleTimeout) }}
+	}
+	r.events = sse.NewDecoder(body)
+	return r
+}
+
+// next returns the data of the next event. It returns io.EOF once the stream is done,
+// an *APIError when the server sends an "error" event or an error payload,
+// ErrStreamTruncated when the body ends before a complete "[DONE]" line, whether or
+// not a blank line follows it, and ErrStreamIdleTimeout
+// when the server stays silent for longer than the idle timeout.
+func (r *streamReader) next() ([]byte, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	if r.idleTimer != nil {
+		r.idleTimer.Reset(r.idleTimeout)
+	}
+	data, err := r.read()
+	if r.idleTimer != nil {
+		r.idleTimer.Stop()
+	}
+	if err != nil && r.timedOut.Load() {
+		err = fmt.Errorf("%w: no data received for %s", ErrStreamIdleTimeout, r.idleTimeout)
+	}
+	if err != nil {
+		r.err = err
+	}
+	return data, err
+}
+
+func (r *streamReader) read() ([]byte, error) {
+	event, err := r.events.Next()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: %w", ErrStreamTruncated, io.ErrUnexpectedEOF)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading stream: %w", err)
+	}
+	if event.Type == "error" || isStreamErrorPayload(event.Data) {
+		return nil, streamAPIError(r.statusCode, event.Data)
+	}
+	if event.Data == streamDone {
+		return nil, io.EOF
+	}
+	if r.normalize != nil {
+		return r.normalize([]byte(event.Data))
+	}
+	return []byte(event.Data), nil
+}
+
+// streamReader returns a reader for the events of a stream response, applying the client's
+// idle timeout and provider.
+func (c *Client) streamReader(resp *http.Response, cancel context.CancelFunc) *streamReader {
+	r := newStreamReader(resp, c.StreamIdleTimeout, cancel)
+	if c.Provider != nil {
+		r.normalize = c.Provider.NormalizeResponse
+	}
+	return r
+}
+
+// activityReader calls onData whenever bytes are read.
+type activityReader struct {
+	r      io.Reader
+	onData func()
+}
+
+func (a *activityReader) Read(p []byte) (int, error) {
+	n, err := a.r.Read(p)
+	if n > 0 {
+		a.onData()
+	}
+	return n, err
+}
@@ -0,0 +1,52 @@
+package deepseek_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cohesion-org/deepseek-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamReader_DoneDetection(t *testing.T) {
+	const chunk = `data: {"id":"1","object":"chat.completion.chunk","choices":[{"index":0,"delta":{"content":"Hi"}}]}` + "\n\n"
+	tests := []struct {
+		name      string
+		body      string
+		truncated bool
+	}{
+		{name: "done with blank line", body: chunk + "data: [DONE]\n\n"},
+		{name: "done without blank line", body: chunk + "data: [DONE]\n"},
+		{name: "done with CRLF", body: chunk + "data: [DONE]\r\n"},
+		{name: "no done", body: chunk, truncated: true},
+		{name: "done cut off", body: chunk + "data: [DO", truncated: true},
+		{name: "done without line ending", body: chunk + "data: [DONE]", truncated: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				io.WriteString(w, tt.body)
+			}))
+			defer ts.Close()
+			client, err := deepseek.NewClientWithOptions("token", deepseek.WithBaseURL(ts.URL+"/"))
+			require.NoError(t, err)
+
+			stream, err := client.CreateChatCompletionStream(context.Background(), testChatRequest())
+			require.NoError(t, err)
+			defer stream.Close()
+			resp, err := deepseek.AccumulateStream(stream)
+			require.NotNil(t, resp)
+			assert.Equal(t, "Hi", resp.Choices[0].Message.Content)
+			if tt.truncated {
+				assert.ErrorIs(t, err, deepseek.ErrStreamTruncated)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
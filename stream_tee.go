@@ -0,0 +1,192 @@
+package deepseek
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// ErrTeeOverflow is returned by Recv on a tee stream that fell more chunks behind the upstream
+// stream than the buffer given to TeeStream.
+var ErrTeeOverflow = errors.New("tee stream buffer overflow")
+
+// errTeeClosed is returned by Recv on a tee stream after its Close was called.
+var errTeeClosed = errors.New("stream closed")
+
+// TeeStream splits one stream into n independent streams that each receive every chunk, in order.
+//
+// The upstream stream is read as fast as it produces chunks, and each consumer reads from its own
+// queue, so a slow or stalled consumer never holds back the others. If buffer is positive, a queue
+// holds at most buffer chunks: a consumer that falls further behind fails with ErrTeeOverflow and is
+// detached, while the others go on. With buffer <= 0 the queues are unbounded and hold the chunks
+// a consumer has not read yet.
+//
+// Closing a tee stream detaches it; the upstream stream is closed when it ends or when all tee
+// streams have been closed or detached. The chunks are shared between consumers and must not be
+// modified. Stream errors, including io.EOF, are delivered to every consumer.
+func TeeStream[T any](stream Stream[T], n, buffer int) ([]Stream[T], error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("tee needs at least one consumer, got %d", n)
+	}
+
+	t := &streamTee[T]{upstream: stream, open: n}
+	branches := make([]*teeBranch[T], n)
+	streams := make([]Stream[T], n)
+	for i := range branches {
+		branches[i] = &teeBranch[T]{tee: t, limit: buffer, ready: make(chan struct{}, 1)}
+		streams[i] = branches[i]
+	}
+	go t.pump(branches)
+	return streams, nil
+}
+
+// streamTee reads the upstream stream and fans its chunks out to the branches.
+type streamTee[T any] struct {
+	upstream  Stream[T]
+	closeOnce sync.Once
+	closeErr  error
+
+	mu   sync.Mutex
+	open int // Number of branches that have not been detached.
+}
+
+func (t *streamTee[T]) pump(branches []*teeBranch[T]) {
+	defer func() { _ = t.closeUpstream() }()
+	defer func() {
+		for _, b := range branches {
+			b.end()
+		}
+	}()
+	for {
+		chunk, err := t.upstream.Recv()
+		if errors.Is(err, io.EOF) {
+			return
+		}
+		result := StreamResult[T]{Chunk: chunk, Err: err}
+		for _, b := range branches {
+			b.push(result)
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (t *streamTee[T]) closeUpstream() error {
+	t.closeOnce.Do(func() { t.closeErr = t.upstream.Close() })
+	return t.closeErr
+}
+
+// detach records that a branch was detached, and closes the upstream after the last one.
+func (t *streamTee[T]) detach() error {
+	t.mu.Lock()
+	t.open--
+	last := t.open == 0
+	t.mu.Unlock()
+	if last {
+		return t.closeUpstream()
+	}
+	return nil
+}
+
+// teeBranch is one consumer of a streamTee.
+type teeBranch[T any] struct {
+	tee        *streamTee[T]
+	limit      int           // Maximum number of queued results, 0 or less for no limit.
+	ready      chan struct{} // Signaled when the state below changes.
+	detachOnce sync.Once
+	detachErr  error
+
+	mu     sync.Mutex
+	queue  []StreamResult[T]
+	ended  bool  // Whether the pump has pushed its last result.
+	closed bool  // Whether Close was called.
+	failed error // ErrTeeOverflow once the queue overflowed.
+
+	err error // Sticky error, returned again by later calls to Recv.
+}
+
+// push queues a result of the upstream stream. It never blocks.
+func (b *teeBranch[T]) push(result StreamResult[T]) {
+	b.mu.Lock()
+	if b.closed || b.failed != nil {
+		b.mu.Unlock()
+		return
+	}
+	overflow := b.limit > 0 && len(b.queue) >= b.limit
+	if overflow {
+		b.failed = ErrTeeOverflow
+		b.queue = nil
+	} else {
+		b.queue = append(b.queue, result)
+	}
+	b.mu.Unlock()
+	b.signal()
+	if overflow {
+		_ = b.detach()
+	}
+}
+
+// end records that no more results will be pushed.
+func (b *teeBranch[T]) end() {
+	b.mu.Lock()
+	b.ended = true
+	b.mu.Unlock()
+	b.signal()
+}
+
+func (b *teeBranch[T]) signal() {
+	select {
+	case b.ready <- struct{}{}:
+	default:
+	}
+}
+
+func (b *teeBranch[T]) detach() error {
+	b.detachOnce.Do(func() { b.detachErr = b.tee.detach() })
+	return b.detachErr
+}
+
+// Recv receives the next chunk of the upstream stream.
+func (b *teeBranch[T]) Recv() (T, error) {
+	var zero T
+	for {
+		if b.err != nil {
+			return zero, b.err
+		}
+		b.mu.Lock()
+		switch {
+		case b.closed:
+			b.err = errTeeClosed
+		case b.failed != nil:
+			b.err = b.failed
+		case len(b.queue) > 0:
+			result := b.queue[0]
+			b.queue[0] = StreamResult[T]{}
+			b.queue = b.queue[1:]
+			b.mu.Unlock()
+			if result.Err != nil {
+				b.err = result.Err
+				return zero, b.err
+			}
+			return result.Chunk, nil
+		case b.ended:
+			b.err = io.EOF
+		}
+		b.mu.Unlock()
+		if b.err == nil {
+			<-b.ready
+		}
+	}
+}
+
+// Close detaches the consumer. The upstream stream is closed once all consumers are detached.
+func (b *teeBranch[T]) Close() error {
+	b.mu.Lock()
+	b.closed = true
+	b.queue = nil
+	b.mu.Unlock()
+	b.signal()
+	return b.detach()
+}
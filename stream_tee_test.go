@@ -0,0 +1,140 @@
+package deepseek_test
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cohesion-org/deepseek-go"
+	"github.com/cohesion-org/deepseek-go/deepseektest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTeeStream(t *testing.T) {
+	chunks := []string{"a ", "b ", "c ", "d ", "e"}
+	stream := newTestStream(t, deepseektest.Response{Chunks: chunks})
+
+	tees, err := deepseek.TeeStream(stream, 3, 0)
+	require.NoError(t, err)
+
+	results := make([]string, len(tees))
+	var wg sync.WaitGroup
+	for i, tee := range tees {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer tee.Close()
+			for {
+				chunk, err := tee.Recv()
+				if errors.Is(err, io.EOF) {
+					return
+				}
+				if !assert.NoError(t, err) {
+					return
+				}
+				if len(chunk.Choices) > 0 && chunk.Choices[0].Delta.Content != nil {
+					results[i] += *chunk.Choices[0].Delta.Content
+				}
+				if i == 0 {
+					time.Sleep(5 * time.Millisecond) // A slow consumer.
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	for _, content := range results {
+		assert.Equal(t, "a b c d e", content)
+	}
+}
+
+func TestTeeStream_CloseDetachesConsumer(t *testing.T) {
+	stream := newTestStream(t, deepseektest.Response{Chunks: []string{"a ", "b ", "c ", "d ", "e"}})
+
+	tees, err := deepseek.TeeStream(stream, 2, 0)
+	require.NoError(t, err)
+
+	_, err = tees[0].Recv()
+	require.NoError(t, err)
+	require.NoError(t, tees[0].Close())
+	_, err = tees[0].Recv()
+	assert.Error(t, err)
+
+	resp, err := deepseek.AccumulateStream(tees[1])
+	require.NoError(t, err)
+	assert.Equal(t, "a b c d e", resp.Choices[0].Message.Content)
+	_, err = tees[1].Recv()
+	assert.True(t, errors.Is(err, io.EOF), "io.EOF is returned again after the end")
+	require.NoError(t, tees[1].Close())
+}
+
+func TestTeeStream_Errors(t *testing.T) {
+	stream := newTestStream(t, deepseektest.Response{Content: "partial", OmitDone: true})
+
+	_, err := deepseek.TeeStream(stream, 0, 0)
+	assert.Error(t, err)
+
+	tees, err := deepseek.TeeStream(stream, 2, 0)
+	require.NoError(t, err)
+	for _, tee := range tees {
+		_, err := deepseek.AccumulateStream(tee)
+		assert.ErrorIs(t, err, deepseek.ErrStreamTruncated, "errors reach every consumer")
+	}
+}
+
+func TestTeeStream_UnreadConsumerDoesNotBlockOthers(t *testing.T) {
+	stream := newTestStream(t, deepseektest.Response{Chunks: []string{"a ", "b ", "c ", "d ", "e"}})
+
+	tees, err := deepseek.TeeStream(stream, 3, 0)
+	require.NoError(t, err)
+	defer tees[0].Close() // Never read.
+
+	for _, tee := range tees[1:] {
+		resp, err := deepseek.AccumulateStream(tee)
+		require.NoError(t, err)
+		assert.Equal(t, "a b c d e", resp.Choices[0].Message.Content)
+		require.NoError(t, tee.Close())
+	}
+}
+
+// gatedStream returns one of its chunks each time a value is sent on next.
+type gatedStream struct {
+	chunks []string
+	next   chan struct{}
+}
+
+func (s *gatedStream) Recv() (string, error) {
+	if len(s.chunks) == 0 {
+		return "", io.EOF
+	}
+	<-s.next
+	chunk := s.chunks[0]
+	s.chunks = s.chunks[1:]
+	return chunk, nil
+}
+
+func (s *gatedStream) Close() error { return nil }
+
+func TestTeeStream_Overflow(t *testing.T) {
+	upstream := &gatedStream{chunks: []string{"a", "b", "c", "d"}, next: make(chan struct{})}
+	tees, err := deepseek.TeeStream[string](upstream, 2, 2)
+	require.NoError(t, err)
+
+	// The second consumer keeps up while the first one never reads.
+	var received string
+	for range 4 {
+		upstream.next <- struct{}{}
+		chunk, err := tees[1].Recv()
+		require.NoError(t, err)
+		received += chunk
+	}
+	_, err = tees[1].Recv()
+	assert.ErrorIs(t, err, io.EOF)
+	assert.Equal(t, "abcd", received)
+
+	_, err = tees[0].Recv()
+	assert.ErrorIs(t, err, deepseek.ErrTeeOverflow, "the third chunk did not fit in the buffer")
+	require.NoError(t, tees[0].Close())
+}
@@ -0,0 +1,112 @@
+package deepseek
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Default delimiters written around the reasoning content by StreamWriter.
+const (
+	DefaultReasoningStart = "<think>\n"
+	DefaultReasoningEnd   = "\n</think>\n\n"
+)
+
+// StreamWriter writes the tokens of a chat completion stream to writers as they arrive,
+// e.g. to a terminal, a websocket and a log file at once.
+//
+// Only the first choice is written. All writers receive the same bytes in the same order;
+// to let them consume at different speeds, give each its own stream with TeeStream instead.
+type StreamWriter struct {
+	Writers []io.Writer // Destinations of the tokens.
+
+	// Reasoning also writes the reasoning content, wrapped in ReasoningStart and ReasoningEnd
+	// so that it is visually separated from the answer.
+	Reasoning      bool
+	ReasoningStart string // Defaults to DefaultReasoningStart.
+	ReasoningEnd   string // Defaults to DefaultReasoningEnd.
+}
+
+// NewStreamWriter creates a StreamWriter writing the content to the given writers.
+func NewStreamWriter(writers ...io.Writer) *StreamWriter {
+	return &StreamWriter{Writers: writers}
+}
+
+// Copy reads the stream until it ends, writing tokens as they arrive, and returns the accumulated
+// response including its Usage. The stream is not closed. If reading or writing fails, the partial
+// response received so far is returned along with the error.
+func (sw *StreamWriter) Copy(stream ChatCompletionStream) (*ChatCompletionResponse, error) {
+	acc := NewStreamAccumulator()
+	w := io.MultiWriter(sw.Writers...)
+	inReasoning := false
+	write := func(s string) error {
+		if s == "" {
+			return nil
+		}
+		_, err := io.WriteString(w, s)
+		return err
+	}
+
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			if inReasoning {
+				if err := write(sw.reasoningEnd()); err != nil {
+					partial, _ := acc.Response()
+					return partial, fmt.Errorf("error writing stream: %w", err)
+				}
+			}
+			return acc.Response()
+		}
+		if err != nil {
+			partial, _ := acc.Response()
+			return partial, fmt.Errorf("error receiving stream: %w", err)
+		}
+		acc.Add(chunk)
+
+		for _, choice := range chunk.Choices {
+			if choice.Index != 0 {
+				continue
+			}
+			var out string
+			if sw.Reasoning && choice.Delta.ReasoningContent != nil && *choice.Delta.ReasoningContent != "" {
+				if !inReasoning {
+					out += sw.reasoningStart()
+					inReasoning = true
+				}
+				out += *choice.Delta.ReasoningContent
+			}
+			if choice.Delta.Content != nil && *choice.Delta.Content != "" {
+				if inReasoning {
+					out += sw.reasoningEnd()
+					inReasoning = false
+				}
+				out += *choice.Delta.Content
+			}
+			if err := write(out); err != nil {
+				partial, _ := acc.Response()
+				return partial, fmt.Errorf("error writing stream: %w", err)
+			}
+		}
+	}
+}
+
+func (sw *StreamWriter) reasoningStart() string {
+	if sw.ReasoningStart != "" {
+		return sw.ReasoningStart
+	}
+	return DefaultReasoningStart
+}
+
+func (sw *StreamWriter) reasoningEnd() string {
+	if sw.ReasoningEnd != "" {
+		return sw.ReasoningEnd
+	}
+	return DefaultReasoningEnd
+}
+
+// CopyStream writes the content of the stream to w as it arrives and returns the accumulated
+// response. It is a shortcut for NewStreamWriter(w).Copy(stream).
+func CopyStream(w io.Writer, stream ChatCompletionStream) (*ChatCompletionResponse, error) {
+	return NewStreamWriter(w).Copy(stream)
+}
@@ -0,0 +1,53 @@
+package deepseek_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/cohesion-org/deepseek-go"
+	"github.com/cohesion-org/deepseek-go/deepseektest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamWriter_Copy(t *testing.T) {
+	stream := newTestStream(t, deepseektest.Response{ReasoningContent: "Two plus two.", Content: "It is four."})
+	defer stream.Close()
+
+	var terminal, log bytes.Buffer
+	writer := deepseek.NewStreamWriter(&terminal, &log)
+	writer.Reasoning = true
+	resp, err := writer.Copy(stream)
+	require.NoError(t, err)
+
+	assert.Equal(t, "<think>\nTwo plus two.\n</think>\n\nIt is four.", terminal.String())
+	assert.Equal(t, terminal.String(), log.String())
+	assert.Equal(t, "It is four.", resp.Choices[0].Message.Content)
+	assert.Equal(t, "Two plus two.", resp.Choices[0].Message.ReasoningContent)
+	assert.NotZero(t, resp.Usage.TotalTokens)
+}
+
+func TestCopyStream_ContentOnly(t *testing.T) {
+	stream := newTestStream(t, deepseektest.Response{ReasoningContent: "Hidden.", Content: "Shown."})
+	defer stream.Close()
+
+	var out bytes.Buffer
+	resp, err := deepseek.CopyStream(&out, stream)
+	require.NoError(t, err)
+	assert.Equal(t, "Shown.", out.String())
+	assert.Equal(t, "Hidden.", resp.Choices[0].Message.ReasoningContent, "reasoning is still accumulated")
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("broken pipe") }
+
+func TestCopyStream_WriteError(t *testing.T) {
+	stream := newTestStream(t, deepseektest.Response{Content: "Hello"})
+	defer stream.Close()
+
+	partial, err := deepseek.CopyStream(failingWriter{}, stream)
+	assert.ErrorContains(t, err, "broken pipe")
+	assert.NotNil(t, partial)
+}
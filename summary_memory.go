@@ -0,0 +1,185 @@
+package deepseek
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// DefaultSummaryPrompt is the system prompt SummaryMemory uses to summarize a conversation.
+const DefaultSummaryPrompt = "You maintain the memory of a conversation between a user and an assistant. " +
+	"Summarize the conversation below in a few short paragraphs. Keep facts, names, numbers, decisions, " +
+	"preferences of the user and open questions; leave out greetings and small talk. If a summary so far " +
+	"is given, update it with the new messages instead of starting over. Reply with the summary only."
+
+// summaryMessagePrefix starts the content of the message that holds the summary in the history.
+const summaryMessagePrefix = "Summary of the earlier conversation:\n"
+
+// ConversationMemory condenses the history of a Conversation before each turn.
+type ConversationMemory interface {
+	// Compact returns the history to keep. The input is not modified.
+	Compact(ctx context.Context, messages []ChatCompletionMessage) ([]ChatCompletionMessage, error)
+}
+
+// SummaryMemory condenses older turns of a conversation into a rolling summary instead of discarding them.
+//
+// Once the history exceeds Threshold tokens, the turns before the last KeepTurns are sent to Client with
+// Prompt, together with the summary so far, and replaced by a single message holding the new summary,
+// placed right after the system messages. Tool calls and their results are summarized together.
+//
+// Use OnSummary to persist the summary, and SetSummary to restore it together with the saved history.
+type SummaryMemory struct {
+	Client    CompletionClient // Client the summarization requests are sent through.
+	Model     string           // Model that writes the summary. Defaults to DeepSeekChat.
+	Prompt    string           // System prompt of the summarization request. Defaults to DefaultSummaryPrompt.
+	Threshold int              // Token count of the history above which it is summarized.
+	KeepTurns int              // Number of recent turns that are never summarized. Values < 1 keep only the latest turn.
+	Role      string           // Role of the summary message. Defaults to ChatMessageRoleSystem.
+	Counter   TokenCounter     // Counts the tokens of a message. Defaults to EstimateMessageTokens.
+
+	// OnSummary, if set, is called with each new summary, e.g. to persist it. If it returns an error,
+	// the history is left unchanged.
+	OnSummary func(ctx context.Context, summary string) error
+
+	mu      sync.Mutex
+	summary string
+}
+
+// NewSummaryMemory creates a SummaryMemory that summarizes with client once the history exceeds threshold tokens.
+func NewSummaryMemory(client CompletionClient, threshold int) *SummaryMemory {
+	return &SummaryMemory{Client: client, Threshold: threshold, KeepTurns: 2}
+}
+
+// Summary returns the current summary, or "" if nothing was summarized yet.
+func (m *SummaryMemory) Summary() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.summary
+}
+
+// SetSummary restores a summary, e.g. one saved by OnSummary, so that the summary message of a restored
+// history is recognized and updated rather than summarized like any other message.
+func (m *SummaryMemory) SetSummary(summary string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.summary = summary
+}
+
+// Compact implements ConversationMemory.
+func (m *SummaryMemory) Compact(ctx context.Context, messages []ChatCompletionMessage) ([]ChatCompletionMessage, error) {
+	if m.Client == nil {
+		return nil, fmt.Errorf("summary memory has no client")
+	}
+	units := splitContextUnits(messages, m.Counter)
+	if m.Threshold <= 0 || contextTokens(units) <= m.Threshold {
+		return messages, nil
+	}
+
+	previous := m.Summary()
+	last := units[len(units)-1].turn
+	keepFrom := last - max(m.KeepTurns, 1)
+	var system, old, recent []ChatCompletionMessage
+	for _, unit := range units {
+		switch {
+		case m.isSummary(unit.messages[0], previous):
+			// Replaced by the new summary.
+		case unit.system && len(old) == 0 && len(recent) == 0:
+			system = append(system, unit.messages...)
+		case unit.turn <= keepFrom && len(recent) == 0:
+			old = append(old, unit.messages...)
+		default:
+			recent = append(recent, unit.messages...)
+		}
+	}
+	if len(old) == 0 {
+		return messages, nil
+	}
+
+	summary, err := m.summarize(ctx, previous, old)
+	if err != nil {
+		return nil, err
+	}
+	if m.OnSummary != nil {
+		if err := m.OnSummary(ctx, summary); err != nil {
+			return nil, err
+		}
+	}
+	m.SetSummary(summary)
+
+	compacted := make([]ChatCompletionMessage, 0, len(system)+1+len(recent))
+	compacted = append(compacted, system...)
+	compacted = append(compacted, m.summaryMessage(summary))
+	return append(compacted, recent...), nil
+}
+
+// summarize asks the model to fold messages into the summary so far.
+func (m *SummaryMemory) summarize(ctx context.Context, previous string, messages []ChatCompletionMessage) (string, error) {
+	var transcript strings.Builder
+	if previous != "" {
+		fmt.Fprintf(&transcript, "Summary so far:\n%s\n\n", previous)
+	}
+	transcript.WriteString("New messages:\n")
+	for _, msg := range messages {
+		writeTranscriptMessage(&transcript, msg)
+	}
+
+	model := m.Model
+	if model == "" {
+		model = DeepSeekChat
+	}
+	prompt := m.Prompt
+	if prompt == "" {
+		prompt = DefaultSummaryPrompt
+	}
+	resp, err := m.Client.CreateChatCompletion(ctx, &ChatCompletionRequest{
+		Model: model,
+		Messages: []ChatCompletionMessage{
+			{Role: ChatMessageRoleSystem, Content: prompt},
+			{Role: ChatMessageRoleUser, Content: transcript.String()},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("summarizing conversation: %w", err)
+	}
+	if len(resp.Choices) == 0 || strings.TrimSpace(resp.Choices[0].Message.Content) == "" {
+		return "", fmt.Errorf("summarizing conversation: response has no summary")
+	}
+	return strings.TrimSpace(resp.Choices[0].Message.Content), nil
+}
+
+// writeTranscriptMessage writes msg as a line of the transcript that is summarized.
+func writeTranscriptMessage(w *strings.Builder, msg ChatCompletionMessage) {
+	switch {
+	case msg.Role == ChatMessageRoleTool:
+		fmt.Fprintf(w, "tool result: %s\n", msg.Content)
+	case len(msg.ToolCalls) > 0:
+		if msg.Content != "" {
+			fmt.Fprintf(w, "%s: %s\n", msg.Role, msg.Content)
+		}
+		for _, call := range msg.ToolCalls {
+			name := ""
+			if call.Function.Name != nil {
+				name = *call.Function.Name
+			}
+			fmt.Fprintf(w, "%s called %s(%s)\n", msg.Role, name, call.Function.Arguments)
+		}
+	default:
+		fmt.Fprintf(w, "%s: %s\n", msg.Role, msg.Content)
+	}
+}
+
+// summaryMessage returns the message that holds summary in the history.
+func (m *SummaryMemory) summaryMessage(summary string) ChatCompletionMessage {
+	role := m.Role
+	if role == "" {
+		role = ChatMessageRoleSystem
+	}
+	return ChatCompletionMessage{Role: role, Content: summaryMessagePrefix + summary}
+}
+
+// isSummary reports whether msg is the message holding summary.
+func (m *SummaryMemory) isSummary(msg ChatCompletionMessage, summary string) bool {
+	want := m.summaryMessage(summary)
+	return summary != "" && len(msg.ToolCalls) == 0 && msg.Role == want.Role && msg.Content == want.Content
+}
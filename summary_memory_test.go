@@ -0,0 +1,115 @@
+package deepseek_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cohesion-org/deepseek-go"
+	"github.com/cohesion-org/deepseek-go/deepseektest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSummaryMemory(t *testing.T, responses ...deepseektest.Response) (*deepseektest.Server, *deepseek.SummaryMemory) {
+	t.Helper()
+	srv, client := newTestServer(t)
+	srv.Enqueue(deepseektest.RouteChat, responses...)
+	memory := deepseek.NewSummaryMemory(client, 50)
+	memory.KeepTurns = 1
+	memory.Counter = countMessages
+	return srv, memory
+}
+
+func TestSummaryMemory_BelowThreshold(t *testing.T) {
+	srv, memory := newTestSummaryMemory(t)
+	memory.Threshold = 80
+
+	compacted, err := memory.Compact(context.Background(), testHistory())
+	require.NoError(t, err)
+	assert.Equal(t, testHistory(), compacted)
+	assert.Empty(t, srv.Requests())
+}
+
+func TestSummaryMemory_Compact(t *testing.T) {
+	srv, memory := newTestSummaryMemory(t,
+		deepseektest.Response{Content: "The user asked three questions."},
+		deepseektest.Response{Content: "The user asked four questions."},
+	)
+	var saved []string
+	memory.OnSummary = func(_ context.Context, summary string) error {
+		saved = append(saved, summary)
+		return nil
+	}
+
+	compacted, err := memory.Compact(context.Background(), testHistory())
+	require.NoError(t, err)
+	require.Len(t, compacted, 3)
+	assert.Equal(t, "system", compacted[0].Content)
+	assert.Equal(t, deepseek.ChatMessageRoleSystem, compacted[1].Role)
+	assert.Contains(t, compacted[1].Content, "The user asked three questions.")
+	assert.Equal(t, "u3", compacted[2].Content)
+	assert.Equal(t, "The user asked three questions.", memory.Summary())
+
+	sent := lastChatRequest(t, srv)
+	assert.Equal(t, deepseek.DeepSeekChat, sent.Model)
+	require.Len(t, sent.Messages, 2)
+	assert.Equal(t, deepseek.DefaultSummaryPrompt, sent.Messages[0].Content)
+	assert.Contains(t, sent.Messages[1].Content, "user: u1\n")
+	assert.Contains(t, sent.Messages[1].Content, "assistant called lookup({})\ntool result: result call_1\n")
+	assert.NotContains(t, sent.Messages[1].Content, "u3")
+
+	// The next compaction updates the summary rather than summarizing it.
+	history := append(compacted,
+		message(deepseek.ChatMessageRoleAssistant, "a3"),
+		message(deepseek.ChatMessageRoleUser, "u4"),
+		message(deepseek.ChatMessageRoleAssistant, "a4"),
+		message(deepseek.ChatMessageRoleUser, "u5"),
+	)
+	compacted, err = memory.Compact(context.Background(), history)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"system", compacted[1].Content, "u5"}, contents(compacted))
+	assert.Contains(t, compacted[1].Content, "The user asked four questions.")
+
+	transcript := lastChatRequest(t, srv).Messages[1].Content
+	assert.Contains(t, transcript, "Summary so far:\nThe user asked three questions.\n")
+	assert.Contains(t, transcript, "user: u3\n")
+	assert.NotContains(t, transcript, "Summary of the earlier conversation")
+	assert.Equal(t, []string{"The user asked three questions.", "The user asked four questions."}, saved)
+}
+
+func TestSummaryMemory_Errors(t *testing.T) {
+	_, memory := newTestSummaryMemory(t,
+		deepseektest.Response{Content: "A summary."},
+		deepseektest.Response{Status: 400},
+	)
+	hookErr := errors.New("disk full")
+	memory.OnSummary = func(context.Context, string) error { return hookErr }
+
+	_, err := memory.Compact(context.Background(), testHistory())
+	assert.ErrorIs(t, err, hookErr)
+	assert.Empty(t, memory.Summary(), "the summary is only kept once it was persisted")
+
+	memory.OnSummary = nil
+	_, err = memory.Compact(context.Background(), testHistory())
+	assert.ErrorIs(t, err, deepseek.ErrInvalidRequest)
+}
+
+func TestConversation_Memory(t *testing.T) {
+	srv, conv := newTestConversation(t,
+		deepseektest.Response{Content: "Summary."},
+		deepseektest.Response{Content: "a3"},
+	)
+	conv.Append(testHistory()[1:7]...)
+	memory := deepseek.NewSummaryMemory(conv.Client, 50)
+	memory.Counter = countMessages
+	memory.KeepTurns = 1
+	conv.Memory = memory
+
+	_, err := conv.Send(context.Background(), "u3")
+	require.NoError(t, err)
+	assert.Len(t, srv.Requests(), 2)
+	summary := "Summary of the earlier conversation:\nSummary."
+	assert.Equal(t, []string{"Be brief.", summary, "u2", "call", "call_1", "a2", "u3", "a3"}, contents(conv.Messages()))
+	assert.Equal(t, []string{"Be brief.", summary, "u2", "call", "call_1", "a2", "u3"}, contents(lastChatRequest(t, srv).Messages))
+}
@@ -0,0 +1,20 @@
+package deepseek_test
+
+import (
+	"testing"
+
+	"github.com/cohesion-org/deepseek-go"
+	"github.com/cohesion-org/deepseek-go/deepseektest"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestServer starts a fake server, closed when the test ends, and returns it with a client
+// configured with opts that sends every request to it.
+func newTestServer(t *testing.T, opts ...deepseek.Option) (*deepseektest.Server, *deepseek.Client) {
+	t.Helper()
+	srv := deepseektest.NewServer()
+	t.Cleanup(srv.Close)
+	client, err := srv.Client(opts...)
+	require.NoError(t, err)
+	return srv, client
+}
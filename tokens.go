@@ -39,11 +39,7 @@ func EstimateTokensFromMessages(messages *ChatCompletionRequest) *TokenEstimate
 	var totalTokens int
 
 	for _, msg := range messages.Messages {
-		// Add tokens for role (system/user/assistant)
-		totalTokens += 2 // Approximate tokens for role
-
-		// Add tokens for content
-		totalTokens += EstimateTokenCount(msg.Content).EstimatedTokens
+		totalTokens += EstimateMessageTokens(msg)
 	}
 
 	for _, tool := range messages.Tools {
@@ -61,3 +57,20 @@ func EstimateTokensFromMessages(messages *ChatCompletionRequest) *TokenEstimate
 		EstimatedTokens: totalTokens,
 	}
 }
+
+// EstimateMessageTokens estimates the number of tokens of a single chat message, including its tool calls.
+func EstimateMessageTokens(msg ChatCompletionMessage) int {
+	// Add tokens for role (system/user/assistant)
+	tokens := 2 // Approximate tokens for role
+
+	// Add tokens for content
+	tokens += EstimateTokenCount(msg.Content).EstimatedTokens
+
+	for _, call := range msg.ToolCalls {
+		if call.Function.Name != nil {
+			tokens += EstimateTokenCount(*call.Function.Name).EstimatedTokens
+		}
+		tokens += EstimateTokenCount(call.Function.Arguments).EstimatedTokens
+	}
+	return tokens
+}
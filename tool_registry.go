@@ -0,0 +1,233 @@
+package deepseek
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// DefaultMaxToolIterations is the number of completions ToolRunner.Run sends when MaxIterations is not set.
+const DefaultMaxToolIterations = 10
+
+// ErrMaxToolIterations is returned by ToolRunner.Run when the model still calls tools after MaxIterations completions.
+var ErrMaxToolIterations = errors.New("model still calls tools after the maximum number of iterations")
+
+// ToolRegistry holds tools and the Go handlers that execute their calls.
+// It is safe for concurrent use.
+type ToolRegistry struct {
+	mu    sync.RWMutex
+	tools map[string]registeredTool
+	order []string
+}
+
+// registeredTool is a tool of a ToolRegistry.
+type registeredTool struct {
+	tool Tool
+	call func(ctx context.Context, arguments string) (string, error)
+}
+
+// ToolArgumentsError is returned by ToolRegistry.Call when the arguments of a call cannot be decoded.
+type ToolArgumentsError struct {
+	Tool      string // Name of the tool.
+	Arguments string // Arguments sent by the model.
+	Err       error  // Error of the decoding.
+}
+
+func (e *ToolArgumentsError) Error() string {
+	return fmt.Sprintf("invalid arguments for tool %s: %v", e.Tool, e.Err)
+}
+
+func (e *ToolArgumentsError) Unwrap() error {
+	return e.Err
+}
+
+// NewToolRegistry creates an empty ToolRegistry.
+func NewToolRegistry() *ToolRegistry {
+	return &ToolRegistry{tools: make(map[string]registeredTool)}
+}
+
+// RegisterTool adds a tool named name to registry, calling handler with the decoded arguments of each call.
+//
+// The parameters of the tool are generated from the struct type A, see NewToolFromStruct. The result of
+// handler is sent back to the model as is if R is a string, and encoded as JSON otherwise.
+// Registering a name again replaces the tool.
+func RegisterTool[A, R any](registry *ToolRegistry, name, description string, handler func(ctx context.Context, args A) (R, error)) error {
+	if name == "" {
+		return fmt.Errorf("tool name cannot be empty")
+	}
+	if handler == nil {
+		return fmt.Errorf("handler of tool %s cannot be nil", name)
+	}
+	tool, err := newToolForType(name, description, reflect.TypeFor[A]())
+	if err != nil {
+		return err
+	}
+
+	call := func(ctx context.Context, arguments string) (string, error) {
+		var args A
+		if arguments == "" {
+			arguments = "{}"
+		}
+		if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+			return "", &ToolArgumentsError{Tool: name, Arguments: arguments, Err: err}
+		}
+		result, err := handler(ctx, args)
+		if err != nil {
+			return "", err
+		}
+		if s, ok := any(result).(string); ok {
+			return s, nil
+		}
+		encoded, err := json.Marshal(result)
+		if err != nil {
+			return "", fmt.Errorf("encoding result of tool %s: %w", name, err)
+		}
+		return string(encoded), nil
+	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	if _, ok := registry.tools[name]; !ok {
+		registry.order = append(registry.order, name)
+	}
+	registry.tools[name] = registeredTool{
+		tool: tool,
+		call: call,
+	}
+	return nil
+}
+
+// Tools returns the tools of the registry in the order they were registered, for ChatCompletionRequest.Tools.
+func (r *ToolRegistry) Tools() []Tool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	tools := make([]Tool, 0, len(r.order))
+	for _, name := range r.order {
+		tools = append(tools, r.tools[name].tool)
+	}
+	return tools
+}
+
+// Call executes a tool call and returns the content of its result. It returns a *ToolArgumentsError if
+// the arguments cannot be decoded, and the error of the handler if it fails.
+func (r *ToolRegistry) Call(ctx context.Context, call ToolCall) (string, error) {
+	name := ""
+	if call.Function.Name != nil {
+		name = *call.Function.Name
+	}
+	r.mu.RLock()
+	tool, ok := r.tools[name]
+	r.mu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("unknown tool %q", name)
+	}
+	return tool.call(ctx, call.Function.Arguments)
+}
+
+// ToolRunner sends a chat completion request and executes the tool calls of the replies with the
+// handlers of a ToolRegistry, until the model replies without calling a tool.
+//
+// A call that fails, because its tool is unknown, its arguments cannot be decoded or its handler returns
+// an error, is answered with the error, so that the model can correct itself. Run stops only if ctx is done.
+type ToolRunner struct {
+	Client        CompletionClient // Client the completions are sent through.
+	Registry      *ToolRegistry    // Tools offered to the model and the handlers of their calls.
+	MaxIterations int              // Maximum number of completions of a run. Defaults to DefaultMaxToolIterations.
+}
+
+// ToolRunResult is the outcome of ToolRunner.Run.
+type ToolRunResult struct {
+	Response   *ChatCompletionResponse // The last response, whose first choice is the final reply if Run succeeded.
+	Messages   []ChatCompletionMessage // The messages of the request, followed by the replies and tool results of the run.
+	Usage      Usage                   // Token usage of all completions of the run.
+	Iterations int                     // Number of completions sent.
+}
+
+// NewToolRunner creates a ToolRunner that sends completions through client and calls the tools of registry.
+func NewToolRunner(client CompletionClient, registry *ToolRegistry) *ToolRunner {
+	return &ToolRunner{Client: client, Registry: registry}
+}
+
+// Run sends request, with the tools of the registry if request.Tools is empty, and executes the tool calls
+// of each reply until the model stops calling tools. The request is not modified.
+//
+// If the model still calls tools after MaxIterations completions, Run returns the result so far with
+// ErrMaxToolIterations. The tool calls of the last reply are not executed then.
+func (r *ToolRunner) Run(ctx context.Context, request *ChatCompletionRequest) (*ToolRunResult, error) {
+	if request == nil {
+		return nil, fmt.Errorf("request cannot be nil")
+	}
+	if r.Registry == nil {
+		return nil, fmt.Errorf("tool runner has no registry")
+	}
+	maxIterations := r.MaxIterations
+	if maxIterations <= 0 {
+		maxIterations = DefaultMaxToolIterations
+	}
+
+	req := *request
+	if len(req.Tools) == 0 {
+		req.Tools = r.Registry.Tools()
+	}
+	result := &ToolRunResult{Messages: append([]ChatCompletionMessage(nil), request.Messages...)}
+	for result.Iterations < maxIterations {
+		req.Messages = result.Messages
+		resp, err := r.Client.CreateChatCompletion(ctx, &req)
+		if err != nil {
+			return result, err
+		}
+		result.Iterations++
+		result.Response = resp
+		addUsage(&result.Usage, resp.Usage)
+		if len(resp.Choices) == 0 {
+			return result, fmt.Errorf("response has no choices")
+		}
+
+		reply := resp.Choices[0].Message
+		result.Messages = append(result.Messages, ChatCompletionMessage{
+			Role:      ChatMessageRoleAssistant,
+			Content:   reply.Content,
+			ToolCalls: reply.ToolCalls,
+		})
+		if len(reply.ToolCalls) == 0 {
+			return result, nil
+		}
+		if result.Iterations == maxIterations {
+			break
+		}
+		for _, call := range reply.ToolCalls {
+			content, err := r.Registry.Call(ctx, call)
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return result, ctxErr
+			}
+			if err != nil {
+				content = "Error: " + err.Error()
+			}
+			id := ""
+			if call.ID != nil {
+				id = *call.ID
+			}
+			result.Messages = append(result.Messages, ChatCompletionMessage{Role: ChatMessageRoleTool, ToolCallID: id, Content: content})
+		}
+	}
+	return result, fmt.Errorf("%w (%d)", ErrMaxToolIterations, maxIterations)
+}
+
+// addUsage adds the token counts of u to total.
+func addUsage(total *Usage, u Usage) {
+	total.PromptTokens += u.PromptTokens
+	total.CompletionTokens += u.CompletionTokens
+	total.TotalTokens += u.TotalTokens
+	total.PromptCacheHitTokens += u.PromptCacheHitTokens
+	total.PromptCacheMissTokens += u.PromptCacheMissTokens
+	total.PromptTokensDetails.CachedTokens += u.PromptTokensDetails.CachedTokens
+	if u.CompletionTokensDetails != nil {
+		if total.CompletionTokensDetails == nil {
+			total.CompletionTokensDetails = &CompletionTokensDetails{}
+		}
+		total.CompletionTokensDetails.ReasoningTokens += u.CompletionTokensDetails.ReasoningTokens
+	}
+}
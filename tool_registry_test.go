@@ -0,0 +1,121 @@
+package deepseek_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cohesion-org/deepseek-go"
+	"github.com/cohesion-org/deepseek-go/deepseektest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type weatherArgs struct {
+	City string `json:"city" description:"Name of the city"`
+}
+
+type weatherResult struct {
+	City        string `json:"city"`
+	Temperature int    `json:"temperature"`
+}
+
+func newTestToolRegistry(t *testing.T) *deepseek.ToolRegistry {
+	t.Helper()
+	registry := deepseek.NewToolRegistry()
+	require.NoError(t, deepseek.RegisterTool(registry, "get_weather", "Get the weather of a city",
+		func(_ context.Context, args weatherArgs) (weatherResult, error) {
+			if args.City == "" {
+				return weatherResult{}, errors.New("city is required")
+			}
+			return weatherResult{City: args.City, Temperature: 21}, nil
+		}))
+	require.NoError(t, deepseek.RegisterTool(registry, "get_time", "Get the current time",
+		func(context.Context, struct{}) (string, error) { return "12:00", nil }))
+	return registry
+}
+
+func weatherCall(arguments string) deepseek.ToolCall {
+	name := "get_weather"
+	return deepseek.ToolCall{Function: deepseek.ToolCallFunction{Name: &name, Arguments: arguments}}
+}
+
+func TestToolRegistry(t *testing.T) {
+	registry := newTestToolRegistry(t)
+	tools := registry.Tools()
+	require.Len(t, tools, 2)
+	assert.Equal(t, "get_weather", tools[0].Function.Name)
+	assert.JSONEq(t, `{"type":"object","properties":{"city":{"type":"string","description":"Name of the city"}},"required":["city"]}`,
+		string(tools[0].Function.Parameters))
+	assert.Equal(t, "get_time", tools[1].Function.Name)
+
+	result, err := registry.Call(context.Background(), weatherCall(`{"city":"Paris"}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"city":"Paris","temperature":21}`, result)
+
+	name := "get_time"
+	result, err = registry.Call(context.Background(), deepseek.ToolCall{Function: deepseek.ToolCallFunction{Name: &name}})
+	require.NoError(t, err)
+	assert.Equal(t, "12:00", result, "string results are not encoded")
+
+	_, err = registry.Call(context.Background(), weatherCall(`{"city":`))
+	var argsErr *deepseek.ToolArgumentsError
+	require.ErrorAs(t, err, &argsErr)
+	assert.Equal(t, "get_weather", argsErr.Tool)
+
+	_, err = registry.Call(context.Background(), weatherCall(`{}`))
+	assert.ErrorContains(t, err, "city is required")
+
+	name = "unknown"
+	_, err = registry.Call(context.Background(), deepseek.ToolCall{Function: deepseek.ToolCallFunction{Name: &name}})
+	assert.ErrorContains(t, err, `unknown tool "unknown"`)
+
+	assert.Error(t, deepseek.RegisterTool(registry, "bad", "Not a struct",
+		func(context.Context, string) (string, error) { return "", nil }))
+}
+
+func TestToolRunner_Run(t *testing.T) {
+	srv, client := newTestServer(t)
+	srv.Enqueue(deepseektest.RouteChat,
+		deepseektest.Response{ToolCalls: []deepseek.ToolCall{weatherCall(`{"city":`)}},
+		deepseektest.Response{ToolCalls: []deepseek.ToolCall{weatherCall(`{"city":"Paris"}`)}},
+		deepseektest.Response{Content: "It is 21 degrees in Paris."},
+	)
+
+	request := &deepseek.ChatCompletionRequest{
+		Model:    deepseek.DeepSeekChat,
+		Messages: []deepseek.ChatCompletionMessage{message(deepseek.ChatMessageRoleUser, "Weather in Paris?")},
+	}
+	result, err := deepseek.NewToolRunner(client, newTestToolRegistry(t)).Run(context.Background(), request)
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Iterations)
+	assert.Equal(t, "It is 21 degrees in Paris.", result.Response.Choices[0].Message.Content)
+	assert.Len(t, request.Messages, 1, "the request is not modified")
+
+	require.Len(t, result.Messages, 6)
+	assert.Contains(t, result.Messages[2].Content, "Error: invalid arguments for tool get_weather", "decode errors are fed back")
+	assert.JSONEq(t, `{"city":"Paris","temperature":21}`, result.Messages[4].Content)
+	assert.Equal(t, *result.Messages[3].ToolCalls[0].ID, result.Messages[4].ToolCallID)
+	assert.Equal(t, "It is 21 degrees in Paris.", result.Messages[5].Content)
+	assert.NotZero(t, result.Usage.TotalTokens)
+
+	sent := lastChatRequest(t, srv)
+	assert.Len(t, sent.Tools, 2, "the tools of the registry are sent")
+	assert.Len(t, sent.Messages, 5)
+}
+
+func TestToolRunner_MaxIterations(t *testing.T) {
+	srv, client := newTestServer(t)
+	srv.SetDefault(deepseektest.RouteChat, deepseektest.Response{ToolCalls: []deepseek.ToolCall{weatherCall(`{"city":"Paris"}`)}})
+
+	runner := deepseek.NewToolRunner(client, newTestToolRegistry(t))
+	runner.MaxIterations = 3
+	result, err := runner.Run(context.Background(), &deepseek.ChatCompletionRequest{
+		Model:    deepseek.DeepSeekChat,
+		Messages: []deepseek.ChatCompletionMessage{message(deepseek.ChatMessageRoleUser, "Weather in Paris?")},
+	})
+	require.ErrorIs(t, err, deepseek.ErrMaxToolIterations)
+	assert.Equal(t, 3, result.Iterations)
+	assert.Len(t, srv.Requests(), 3)
+	assert.Len(t, result.Messages, 6, "the calls of the last reply are not executed")
+}
@@ -0,0 +1,296 @@
+package deepseek
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JSONSchema is the subset of JSON Schema used to describe the parameters of a tool.
+type JSONSchema struct {
+	Type                 string                 `json:"type,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Enum                 []any                  `json:"enum,omitempty"`
+	Default              any                    `json:"default,omitempty"`
+	Minimum              *float64               `json:"minimum,omitempty"`
+	Maximum              *float64               `json:"maximum,omitempty"`
+	MinLength            *int                   `json:"minLength,omitempty"`
+	MaxLength            *int                   `json:"maxLength,omitempty"`
+	MinItems             *int                   `json:"minItems,omitempty"`
+	MaxItems             *int                   `json:"maxItems,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties *JSONSchema            `json:"additionalProperties,omitempty"`
+}
+
+// NewToolFromStruct returns a function tool whose parameters are described by the struct type of v,
+// so that the arguments of its calls can be decoded into that same type.
+//
+// Fields are named after their json tags. A field is required unless its json tag has omitempty, it is
+// a pointer, or it is tagged required:"false"; required:"true" always makes it required. These tags
+// add constraints:
+//
+//	description:"..."  describes the field to the model.
+//	enum:"a,b,c"       lists the allowed values, separated by commas, or those of the items of a slice.
+//	min:"1" max:"10"   bound numbers, the length of strings, or the number of items of slices.
+//	default:"..."      documents the value used when the field is omitted.
+//
+// For example:
+//
+//	type WeatherArgs struct {
+//		City string `json:"city" description:"Name of the city"`
+//		Unit string `json:"unit,omitempty" enum:"celsius,fahrenheit" default:"celsius"`
+//		Days int    `json:"days,omitempty" min:"1" max:"7"`
+//	}
+//	tool, err := deepseek.NewToolFromStruct("get_weather", "Get the weather forecast of a city", WeatherArgs{})
+func NewToolFromStruct(name, description string, v any) (Tool, error) {
+	if v == nil {
+		return Tool{}, fmt.Errorf("cannot generate a schema for nil")
+	}
+	return newToolForType(name, description, reflect.TypeOf(v))
+}
+
+// newToolForType returns a function tool whose parameters are the JSON Schema of the struct type t.
+func newToolForType(name, description string, t reflect.Type) (Tool, error) {
+	schema, err := SchemaForType(t)
+	if err != nil {
+		return Tool{}, fmt.Errorf("tool %s: %w", name, err)
+	}
+	if schema.Type != "object" {
+		return Tool{}, fmt.Errorf("parameters of tool %s must be a struct, got %s", name, t)
+	}
+	parameters, err := json.Marshal(schema)
+	if err != nil {
+		return Tool{}, fmt.Errorf("encoding parameters of tool %s: %w", name, err)
+	}
+	return Tool{
+		Type:     "function",
+		Function: Function{Name: name, Description: description, Parameters: parameters},
+	}, nil
+}
+
+// GenerateSchema returns the JSON Schema of the type of v, see NewToolFromStruct for the supported tags.
+func GenerateSchema(v any) (*JSONSchema, error) {
+	if v == nil {
+		return nil, fmt.Errorf("cannot generate a schema for nil")
+	}
+	return SchemaForType(reflect.TypeOf(v))
+}
+
+// SchemaForType returns the JSON Schema of t, see NewToolFromStruct for the supported tags.
+// Recursive types are not supported.
+func SchemaForType(t reflect.Type) (*JSONSchema, error) {
+	return schemaForType(t, map[reflect.Type]bool{})
+}
+
+var (
+	timeType       = reflect.TypeFor[time.Time]()
+	rawMessageType = reflect.TypeFor[json.RawMessage]()
+)
+
+// schemaForType returns the schema of t. visiting holds the structs being generated, to detect recursion.
+func schemaForType(t reflect.Type, visiting map[reflect.Type]bool) (*JSONSchema, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return &JSONSchema{Type: "string", Format: "date-time"}, nil
+	case t == rawMessageType:
+		return &JSONSchema{}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &JSONSchema{Type: "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &JSONSchema{Type: "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Type: "number"}, nil
+	case reflect.String:
+		return &JSONSchema{Type: "string"}, nil
+	case reflect.Interface:
+		return &JSONSchema{}, nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 && t.Kind() == reflect.Slice {
+			return &JSONSchema{Type: "string", Description: "Base64 encoded data"}, nil
+		}
+		items, err := schemaForType(t.Elem(), visiting)
+		if err != nil {
+			return nil, err
+		}
+		return &JSONSchema{Type: "array", Items: items}, nil
+	case reflect.Map:
+		values, err := schemaForType(t.Elem(), visiting)
+		if err != nil {
+			return nil, err
+		}
+		return &JSONSchema{Type: "object", AdditionalProperties: values}, nil
+	case reflect.Struct:
+		if visiting[t] {
+			return nil, fmt.Errorf("cannot generate a schema for recursive type %s", t)
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+		schema := &JSONSchema{Type: "object", Properties: map[string]*JSONSchema{}}
+		if err := addStructFields(schema, t, visiting); err != nil {
+			return nil, err
+		}
+		return schema, nil
+	default:
+		return nil, fmt.Errorf("cannot generate a schema for type %s", t)
+	}
+}
+
+// addStructFields adds the fields of struct t to schema, flattening embedded structs like encoding/json.
+func addStructFields(schema *JSONSchema, t reflect.Type, visiting map[reflect.Type]bool) error {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				if visiting[embedded] {
+					return fmt.Errorf("cannot generate a schema for recursive type %s", embedded)
+				}
+				visiting[embedded] = true
+				err := addStructFields(schema, embedded, visiting)
+				delete(visiting, embedded)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property, err := schemaForType(field.Type, visiting)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		if err := applyFieldTags(property, field); err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		schema.Properties[name] = property
+
+		required := !strings.Contains(","+options+",", ",omitempty,") && field.Type.Kind() != reflect.Pointer
+		if value, ok := field.Tag.Lookup("required"); ok {
+			if required, err = strconv.ParseBool(value); err != nil {
+				return fmt.Errorf("field %s: invalid required tag %q", field.Name, value)
+			}
+		}
+		if required {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	return nil
+}
+
+// applyFieldTags adds the constraints of the tags of field to its schema.
+func applyFieldTags(schema *JSONSchema, field reflect.StructField) error {
+	if description, ok := field.Tag.Lookup("description"); ok {
+		schema.Description = description
+	}
+	if enum, ok := field.Tag.Lookup("enum"); ok {
+		target := schema
+		if schema.Type == "array" {
+			target = schema.Items
+		}
+		for _, value := range strings.Split(enum, ",") {
+			parsed, err := parseSchemaValue(target.Type, strings.TrimSpace(value))
+			if err != nil {
+				return fmt.Errorf("invalid enum tag: %w", err)
+			}
+			target.Enum = append(target.Enum, parsed)
+		}
+	}
+	if value, ok := field.Tag.Lookup("default"); ok {
+		parsed, err := parseSchemaValue(schema.Type, value)
+		if err != nil {
+			return fmt.Errorf("invalid default tag: %w", err)
+		}
+		schema.Default = parsed
+	}
+	for _, bound := range []string{"min", "max"} {
+		value, ok := field.Tag.Lookup(bound)
+		if !ok {
+			continue
+		}
+		if err := applyBound(schema, bound, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyBound sets the min or max bound of schema that matches its type.
+func applyBound(schema *JSONSchema, bound, value string) error {
+	switch schema.Type {
+	case "integer", "number":
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid %s tag %q", bound, value)
+		}
+		if bound == "min" {
+			schema.Minimum = &n
+		} else {
+			schema.Maximum = &n
+		}
+	case "string", "array":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid %s tag %q", bound, value)
+		}
+		switch {
+		case schema.Type == "string" && bound == "min":
+			schema.MinLength = &n
+		case schema.Type == "string":
+			schema.MaxLength = &n
+		case bound == "min":
+			schema.MinItems = &n
+		default:
+			schema.MaxItems = &n
+		}
+	default:
+		return fmt.Errorf("%s tag is not supported for type %q", bound, schema.Type)
+	}
+	return nil
+}
+
+// parseSchemaValue parses a value of an enum or default tag as a value of the given schema type.
+func parseSchemaValue(schemaType, value string) (any, error) {
+	switch schemaType {
+	case "integer":
+		return strconv.ParseInt(value, 10, 64)
+	case "number":
+		return strconv.ParseFloat(value, 64)
+	case "boolean":
+		return strconv.ParseBool(value)
+	case "string":
+		return value, nil
+	default:
+		var parsed any
+		if err := json.Unmarshal([]byte(value), &parsed); err != nil {
+			return nil, fmt.Errorf("%q is not valid JSON", value)
+		}
+		return parsed, nil
+	}
+}
@@ -0,0 +1,144 @@
+package deepseek_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/cohesion-org/deepseek-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Address struct {
+	Street string `json:"street"`
+	City   string `json:"city" description:"City name"`
+}
+
+type Metadata struct {
+	Source string `json:"source,omitempty"`
+}
+
+type BookingArgs struct {
+	Metadata
+	Guest    string            `json:"guest" description:"Full name of the guest" min:"2" max:"100"`
+	Nights   int               `json:"nights" min:"1" max:"30" default:"1"`
+	Room     string            `json:"room,omitempty" enum:"single,double,suite" default:"double"`
+	Price    float64           `json:"price,omitempty" min:"0.5"`
+	Extras   []string          `json:"extras,omitempty" enum:"breakfast,parking" max:"2"`
+	Address  *Address          `json:"address"`
+	CheckIn  time.Time         `json:"check_in"`
+	Notes    map[string]string `json:"notes,omitempty"`
+	Pets     bool              `json:"pets" required:"false"`
+	Optional *int              `json:"optional" required:"true"`
+	Ignored  string            `json:"-"`
+	internal string
+}
+
+func TestGenerateSchema(t *testing.T) {
+	schema, err := deepseek.GenerateSchema(BookingArgs{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "object", schema.Type)
+	assert.Equal(t, []string{"guest", "nights", "check_in", "optional"}, schema.Required, "pointers are optional unless tagged")
+	assert.NotContains(t, schema.Properties, "Ignored")
+	assert.NotContains(t, schema.Properties, "internal")
+	assert.Equal(t, "string", schema.Properties["source"].Type, "embedded fields are flattened")
+
+	guest := schema.Properties["guest"]
+	assert.Equal(t, "Full name of the guest", guest.Description)
+	assert.Equal(t, 2, *guest.MinLength)
+	assert.Equal(t, 100, *guest.MaxLength)
+
+	nights := schema.Properties["nights"]
+	assert.Equal(t, "integer", nights.Type)
+	assert.Equal(t, 1.0, *nights.Minimum)
+	assert.Equal(t, 30.0, *nights.Maximum)
+	assert.Equal(t, int64(1), nights.Default)
+
+	room := schema.Properties["room"]
+	assert.Equal(t, []any{"single", "double", "suite"}, room.Enum)
+	assert.Equal(t, "double", room.Default)
+	assert.Equal(t, 0.5, *schema.Properties["price"].Minimum)
+
+	extras := schema.Properties["extras"]
+	assert.Equal(t, "array", extras.Type)
+	assert.Equal(t, []any{"breakfast", "parking"}, extras.Items.Enum)
+	assert.Equal(t, 2, *extras.MaxItems)
+
+	address := schema.Properties["address"]
+	assert.Equal(t, "object", address.Type)
+	assert.Equal(t, []string{"street", "city"}, address.Required)
+	assert.Equal(t, "City name", address.Properties["city"].Description)
+
+	assert.Equal(t, "date-time", schema.Properties["check_in"].Format)
+	assert.Equal(t, "string", schema.Properties["notes"].AdditionalProperties.Type)
+	assert.Equal(t, "boolean", schema.Properties["pets"].Type)
+	assert.Equal(t, "integer", schema.Properties["optional"].Type)
+}
+
+func TestGenerateSchema_Errors(t *testing.T) {
+	type Node struct {
+		Children []Node `json:"children"`
+	}
+	type List struct {
+		*List
+		Value int `json:"value"`
+	}
+	_, err := deepseek.GenerateSchema(Node{})
+	assert.ErrorContains(t, err, "recursive")
+
+	_, err = deepseek.GenerateSchema(List{})
+	assert.ErrorContains(t, err, "recursive", "a struct that embeds itself")
+	_, err = deepseek.NewToolFromStruct("walk", "Walk a list", List{})
+	assert.ErrorContains(t, err, "recursive")
+	err = deepseek.RegisterTool(deepseek.NewToolRegistry(), "walk", "Walk a list",
+		func(ctx context.Context, args List) (string, error) { return "", nil })
+	assert.ErrorContains(t, err, "recursive")
+
+	_, err = deepseek.GenerateSchema(struct {
+		Callback func() `json:"callback"`
+	}{})
+	assert.ErrorContains(t, err, "field Callback")
+
+	_, err = deepseek.GenerateSchema(struct {
+		Count int `json:"count" min:"many"`
+	}{})
+	assert.ErrorContains(t, err, "invalid min tag")
+
+	_, err = deepseek.GenerateSchema(struct {
+		Count int `json:"count" enum:"1,two"`
+	}{})
+	assert.ErrorContains(t, err, "invalid enum tag")
+
+	_, err = deepseek.GenerateSchema(nil)
+	assert.Error(t, err)
+}
+
+func TestNewToolFromStruct(t *testing.T) {
+	type WeatherArgs struct {
+		City string `json:"city" description:"Name of the city"`
+		Unit string `json:"unit,omitempty" enum:"celsius,fahrenheit"`
+	}
+	tool, err := deepseek.NewToolFromStruct("get_weather", "Get the weather of a city", &WeatherArgs{})
+	require.NoError(t, err)
+	assert.Equal(t, "function", tool.Type)
+	assert.Equal(t, "get_weather", tool.Function.Name)
+	assert.JSONEq(t, `{
+		"type": "object",
+		"properties": {
+			"city": {"type": "string", "description": "Name of the city"},
+			"unit": {"type": "string", "enum": ["celsius", "fahrenheit"]}
+		},
+		"required": ["city"]
+	}`, string(tool.Function.Parameters))
+
+	// The arguments of a call decode into the same type.
+	var args WeatherArgs
+	require.NoError(t, json.Unmarshal([]byte(`{"city":"Paris","unit":"celsius"}`), &args))
+	assert.Equal(t, WeatherArgs{City: "Paris", Unit: "celsius"}, args)
+
+	_, err = deepseek.NewToolFromStruct("bad", "Not a struct", "text")
+	assert.ErrorContains(t, err, "must be a struct")
+}
@@ -0,0 +1,129 @@
+package deepseek
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+var (
+	// ErrToolCallsIncomplete is returned when tool calls are requested before the stream finished with "tool_calls".
+	ErrToolCallsIncomplete = errors.New("tool calls are incomplete")
+	// ErrInvalidToolCall is returned when a merged tool call is missing its ID or name, or its arguments are not valid JSON.
+	ErrInvalidToolCall = errors.New("invalid tool call")
+)
+
+// ToolCallMerger merges the tool call fragments of a streamed choice into complete tool calls.
+//
+// In streaming mode the first fragment of a call carries its ID, type and function name, and later
+// fragments with the same ToolCall.Index only append to Function.Arguments. Parallel tool calls use
+// distinct indexes. A fragment carrying a new ID for an index that already has a different ID starts
+// a new call, which covers providers that reuse index 0 for every call.
+//
+// A ToolCallMerger can be used on its own or through a StreamAccumulator. It is not safe for concurrent use.
+type ToolCallMerger struct {
+	calls    []ToolCall
+	finished bool
+}
+
+// NewToolCallMerger creates an empty ToolCallMerger.
+func NewToolCallMerger() *ToolCallMerger {
+	return &ToolCallMerger{}
+}
+
+// AddChoice merges the tool call fragments of a streamed choice and records its finish reason.
+func (m *ToolCallMerger) AddChoice(choice StreamChoices) {
+	m.Add(choice.Delta.ToolCalls...)
+	if choice.FinishReason != nil && *choice.FinishReason == FinishReasonToolCalls {
+		m.finished = true
+	}
+}
+
+// Add merges tool call fragments.
+func (m *ToolCallMerger) Add(fragments ...ToolCall) {
+	for _, fragment := range fragments {
+		m.add(fragment)
+	}
+}
+
+func (m *ToolCallMerger) add(fragment ToolCall) {
+	hasID := fragment.ID != nil && *fragment.ID != ""
+	for i := len(m.calls) - 1; i >= 0; i-- {
+		call := &m.calls[i]
+		if call.Index != fragment.Index {
+			continue
+		}
+		if hasID && call.ID != nil && *call.ID != *fragment.ID {
+			break // A new call reusing the index.
+		}
+		if hasID {
+			call.ID = fragment.ID
+		}
+		if fragment.Type != nil && *fragment.Type != "" {
+			call.Type = fragment.Type
+		}
+		if fragment.Function.Name != nil && *fragment.Function.Name != "" {
+			call.Function.Name = fragment.Function.Name
+		}
+		call.Function.Arguments += fragment.Function.Arguments
+		return
+	}
+	m.calls = append(m.calls, fragment)
+}
+
+// Finish marks the tool calls as complete, for callers that track the finish reason themselves.
+func (m *ToolCallMerger) Finish() {
+	m.finished = true
+}
+
+// Done reports whether the stream finished with finish_reason "tool_calls".
+func (m *ToolCallMerger) Done() bool {
+	return m.finished
+}
+
+// Len returns the number of distinct tool calls seen so far.
+func (m *ToolCallMerger) Len() int {
+	return len(m.calls)
+}
+
+// Partial returns the tool calls merged so far, ordered by index, without validation.
+func (m *ToolCallMerger) Partial() []ToolCall {
+	if len(m.calls) == 0 {
+		return nil
+	}
+	calls := append([]ToolCall(nil), m.calls...)
+	sort.SliceStable(calls, func(i, j int) bool { return calls[i].Index < calls[j].Index })
+	return calls
+}
+
+// ToolCalls returns the complete tool calls, ordered by index. It fails with ErrToolCallsIncomplete
+// until the finish reason "tool_calls" was seen (or Finish was called), and with ErrInvalidToolCall
+// if a call has no ID or name or if its arguments are not valid JSON. Empty arguments are accepted.
+func (m *ToolCallMerger) ToolCalls() ([]ToolCall, error) {
+	if !m.finished {
+		return nil, ErrToolCallsIncomplete
+	}
+	calls := m.Partial()
+	for _, call := range calls {
+		if err := validateToolCall(call); err != nil {
+			return nil, err
+		}
+	}
+	return calls, nil
+}
+
+// validateToolCall checks that a merged tool call can be sent back to the API.
+func validateToolCall(call ToolCall) error {
+	if call.ID == nil || *call.ID == "" {
+		return fmt.Errorf("%w: tool call %d has no ID", ErrInvalidToolCall, call.Index)
+	}
+	if call.Function.Name == nil || *call.Function.Name == "" {
+		return fmt.Errorf("%w: tool call %s has no function name", ErrInvalidToolCall, *call.ID)
+	}
+	if call.Function.Arguments != "" && !json.Valid([]byte(call.Function.Arguments)) {
+		return fmt.Errorf("%w: arguments of %s (%s) are not valid JSON: %q",
+			ErrInvalidToolCall, *call.Function.Name, *call.ID, call.Function.Arguments)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package deepseek_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cohesion-org/deepseek-go"
+	"github.com/cohesion-org/deepseek-go/deepseektest"
+	"github.com/cohesion-org/deepseek-go/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func toolCallHead(index int, id, name string) deepseek.ToolCall {
+	return deepseek.ToolCall{
+		Index:    index,
+		ID:       utils.StringPtr(id),
+		Type:     utils.StringPtr("function"),
+		Function: deepseek.ToolCallFunction{Name: utils.StringPtr(name)},
+	}
+}
+
+func toolCallArgs(index int, args string) deepseek.ToolCall {
+	return deepseek.ToolCall{Index: index, Function: deepseek.ToolCallFunction{Arguments: args}}
+}
+
+func TestToolCallMerger_ParallelCalls(t *testing.T) {
+	merger := deepseek.NewToolCallMerger()
+
+	// Fragments of two parallel calls arrive interleaved.
+	merger.Add(toolCallHead(1, "call_b", "get_time"), toolCallHead(0, "call_a", "get_weather"))
+	merger.Add(toolCallArgs(0, `{"city":`), toolCallArgs(1, `{"tz":"UTC"}`))
+	merger.Add(toolCallArgs(0, `"Paris"}`))
+	assert.Equal(t, 2, merger.Len())
+
+	_, err := merger.ToolCalls()
+	assert.ErrorIs(t, err, deepseek.ErrToolCallsIncomplete)
+
+	partial := merger.Partial()
+	require.Len(t, partial, 2)
+	assert.Equal(t, "call_a", *partial[0].ID)
+
+	merger.AddChoice(deepseek.StreamChoices{FinishReason: finishReasonPtr(deepseek.FinishReasonToolCalls)})
+	assert.True(t, merger.Done())
+
+	calls, err := merger.ToolCalls()
+	require.NoError(t, err)
+	require.Len(t, calls, 2)
+	assert.Equal(t, "get_weather", *calls[0].Function.Name)
+	assert.Equal(t, `{"city":"Paris"}`, calls[0].Function.Arguments)
+	assert.Equal(t, "get_time", *calls[1].Function.Name)
+	assert.Equal(t, `{"tz":"UTC"}`, calls[1].Function.Arguments)
+}
+
+func TestToolCallMerger_ReusedIndex(t *testing.T) {
+	merger := deepseek.NewToolCallMerger()
+	merger.Add(toolCallHead(0, "call_a", "first"), toolCallArgs(0, `{}`))
+	merger.Add(toolCallHead(0, "call_b", "second"), toolCallArgs(0, `{"n":`), toolCallArgs(0, `2}`))
+	merger.Finish()
+
+	calls, err := merger.ToolCalls()
+	require.NoError(t, err)
+	require.Len(t, calls, 2)
+	assert.Equal(t, "call_a", *calls[0].ID)
+	assert.Equal(t, `{}`, calls[0].Function.Arguments)
+	assert.Equal(t, "call_b", *calls[1].ID)
+	assert.Equal(t, `{"n":2}`, calls[1].Function.Arguments)
+}
+
+func TestToolCallMerger_Validation(t *testing.T) {
+	tests := []struct {
+		name      string
+		fragments []deepseek.ToolCall
+		wantErr   bool
+	}{
+		{name: "empty arguments", fragments: []deepseek.ToolCall{toolCallHead(0, "call_a", "ping")}},
+		{name: "truncated arguments", fragments: []deepseek.ToolCall{toolCallHead(0, "call_a", "ping"), toolCallArgs(0, `{"x":`)}, wantErr: true},
+		{name: "missing id", fragments: []deepseek.ToolCall{{Function: deepseek.ToolCallFunction{Name: utils.StringPtr("ping")}}}, wantErr: true},
+		{name: "missing name", fragments: []deepseek.ToolCall{{ID: utils.StringPtr("call_a")}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merger := deepseek.NewToolCallMerger()
+			merger.Add(tt.fragments...)
+			merger.Finish()
+			_, err := merger.ToolCalls()
+			if tt.wantErr {
+				assert.ErrorIs(t, err, deepseek.ErrInvalidToolCall)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestStreamAccumulator_ParallelToolCalls(t *testing.T) {
+	srv, client := newTestServer(t)
+	srv.Enqueue(deepseektest.RouteChat, deepseektest.Response{
+		ToolCalls: []deepseek.ToolCall{
+			{Function: deepseek.ToolCallFunction{Name: utils.StringPtr("get_weather"), Arguments: `{"city":"Paris"}`}},
+			{Function: deepseek.ToolCallFunction{Name: utils.StringPtr("get_time"), Arguments: `{"tz":"Europe/Paris"}`}},
+		},
+	})
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), testChatRequest())
+	require.NoError(t, err)
+	defer stream.Close()
+
+	resp, err := deepseek.AccumulateStream(stream)
+	require.NoError(t, err)
+	calls := resp.Choices[0].Message.ToolCalls
+	require.Len(t, calls, 2)
+	assert.Equal(t, `{"city":"Paris"}`, calls[0].Function.Arguments)
+	assert.Equal(t, "get_time", *calls[1].Function.Name)
+	assert.Equal(t, `{"tz":"Europe/Paris"}`, calls[1].Function.Arguments)
+}
+
+func TestStreamAccumulator_InvalidToolCall(t *testing.T) {
+	acc := deepseek.NewStreamAccumulator()
+	acc.Add(&deepseek.StreamChatCompletionResponse{Choices: []deepseek.StreamChoices{{
+		Delta: deepseek.StreamDelta{ToolCalls: []deepseek.ToolCall{toolCallHead(0, "call_a", "ping"), toolCallArgs(0, `{"x":`)}},
+	}}})
+	acc.Add(&deepseek.StreamChatCompletionResponse{Choices: []deepseek.StreamChoices{{
+		FinishReason: finishReasonPtr(deepseek.FinishReasonToolCalls),
+	}}})
+
+	resp, err := acc.Response()
+	assert.ErrorIs(t, err, deepseek.ErrInvalidToolCall)
+	require.NotNil(t, resp, "the response is still returned for inspection")
+	assert.Equal(t, `{"x":`, resp.Choices[0].Message.ToolCalls[0].Function.Arguments)
+}